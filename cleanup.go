@@ -0,0 +1,135 @@
+package containers
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// cleanupTimeout limits termination of registered containers,
+// containers are terminated this time before go test -timeout kills the test binary.
+const cleanupTimeout = 10 * time.Second
+
+var processStart = time.Now()
+
+var cleanups = &cleanupRegistry{
+	terminaters: make(map[uint64]Terminater),
+}
+
+type cleanupRegistry struct {
+	watchOnce   sync.Once
+	mu          sync.Mutex
+	nextID      uint64
+	terminaters map[uint64]Terminater
+}
+
+// RegisterCleanup terminates trm when the process receives SIGINT or SIGTERM or go test -timeout is about to expire,
+// unregister must be called when trm is terminated by the owner.
+func RegisterCleanup(trm Terminater) (unregister func()) {
+	cleanups.watchOnce.Do(cleanups.watch)
+
+	cleanups.mu.Lock()
+	id := cleanups.nextID
+	cleanups.nextID++
+	cleanups.terminaters[id] = trm
+	cleanups.mu.Unlock()
+
+	return sync.OnceFunc(func() {
+		cleanups.mu.Lock()
+		delete(cleanups.terminaters, id)
+		cleanups.mu.Unlock()
+	})
+}
+
+func (r *cleanupRegistry) watch() {
+	signalCh := make(chan os.Signal, 1)
+	signal.Notify(signalCh, os.Interrupt, syscall.SIGTERM)
+
+	var timeoutCh <-chan time.Time
+
+	timeout := testTimeout()
+	if timeout > cleanupTimeout {
+		timeoutCh = time.After(time.Until(processStart.Add(timeout - cleanupTimeout)))
+	}
+
+	go func() {
+		for {
+			select {
+			case sig := <-signalCh:
+				Logger().Info("terminate containers on signal", "signal", sig.String())
+
+				r.terminateAll()
+				reraise(sig)
+
+				return
+			case <-timeoutCh:
+				Logger().Info("terminate containers, test timeout is about to expire", "timeout", timeout)
+
+				timeoutCh = nil
+
+				r.terminateAll()
+			}
+		}
+	}()
+}
+
+func (r *cleanupRegistry) terminateAll() {
+	r.mu.Lock()
+	terminaters := make([]Terminater, 0, len(r.terminaters))
+
+	for _, trm := range r.terminaters {
+		terminaters = append(terminaters, trm)
+	}
+
+	clear(r.terminaters)
+	r.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+	defer cancel()
+
+	err := terminateAll(ctx, terminaters)
+	if err != nil {
+		Logger().Error("failed terminate containers", "error", err)
+	}
+}
+
+// reraise kills the process with the default handler of sig, so exit status is the same as without cleanup.
+func reraise(sig os.Signal) {
+	signal.Reset(sig)
+
+	process, err := os.FindProcess(os.Getpid())
+	if err == nil {
+		err = process.Signal(sig)
+	}
+
+	if err == nil {
+		time.Sleep(time.Second)
+	}
+
+	os.Exit(1)
+}
+
+func testTimeout() time.Duration {
+	if !testing.Testing() {
+		return 0
+	}
+
+	timeoutFlag := flag.Lookup("test.timeout")
+	if timeoutFlag == nil {
+		return 0
+	}
+
+	getter, ok := timeoutFlag.Value.(flag.Getter)
+	if !ok {
+		return 0
+	}
+
+	timeout, _ := getter.Get().(time.Duration)
+
+	return timeout
+}
@@ -0,0 +1,63 @@
+package containers_test
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/amidgo/containers"
+)
+
+const cleanupMarkerEnvName = "CONTAINERS_TEST_CLEANUP_MARKER"
+
+type markerContainer struct {
+	path string
+}
+
+func (m markerContainer) Terminate(context.Context) error {
+	return os.WriteFile(m.path, []byte("terminated"), 0o644)
+}
+
+func Test_RegisterCleanup_Signal(t *testing.T) {
+	markerPath := os.Getenv(cleanupMarkerEnvName)
+	if markerPath != "" {
+		containers.RegisterCleanup(markerContainer{path: markerPath})
+		unregister := containers.RegisterCleanup(markerContainer{path: markerPath + ".unregistered"})
+		unregister()
+
+		process, _ := os.FindProcess(os.Getpid())
+		_ = process.Signal(os.Interrupt)
+
+		time.Sleep(time.Minute)
+
+		return
+	}
+
+	if runtime.GOOS == "windows" {
+		t.Skip("interrupt signal can't be sent on windows")
+	}
+
+	markerPath = filepath.Join(t.TempDir(), "marker")
+
+	cmd := exec.Command(os.Args[0], "-test.run", "^Test_RegisterCleanup_Signal$")
+	cmd.Env = append(os.Environ(), cleanupMarkerEnvName+"="+markerPath)
+
+	err := cmd.Run()
+	if err == nil {
+		t.Fatal("expected process killed by signal")
+	}
+
+	_, err = os.Stat(markerPath)
+	if err != nil {
+		t.Fatalf("registered container not terminated, %s", err)
+	}
+
+	_, err = os.Stat(markerPath + ".unregistered")
+	if err == nil {
+		t.Fatal("unregistered container terminated")
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/amidgo/containers/internal/docker"
+)
+
+const usage = `usage: containers <command> [flags]
+
+commands:
+  prune    remove containers of finished go test sessions started by github.com/amidgo/containers,
+           except keep-alive ones, -session removes containers of the session, -all removes every session
+  stop     remove keep-alive containers started with CONTAINERS_KEEP_ALIVE=true
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	var err error
+
+	switch os.Args[1] {
+	case "prune":
+		err = prune(ctx, os.Args[2:])
+	case "stop":
+		err = stop(ctx)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func prune(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("prune", flag.ExitOnError)
+	session := flags.String("session", "", "remove only containers of the testcontainers session")
+	all := flags.Bool("all", false, "remove containers of all sessions, including running ones")
+
+	_ = flags.Parse(args)
+
+	labels := map[string]string{
+		docker.LabelLibrary: "true",
+	}
+
+	if *session != "" {
+		labels[docker.LabelSession] = *session
+	}
+
+	filter := func(cntLabels map[string]string) bool {
+		_, keepAlive := cntLabels[docker.LabelKeepAlive]
+		if keepAlive {
+			return false
+		}
+
+		return *all || *session != "" || docker.Stale(cntLabels)
+	}
+
+	removedIDs, err := docker.RemoveContainers(ctx, labels, filter)
+	printIDs(removedIDs)
+
+	if err != nil {
+		return fmt.Errorf("prune containers, %w", err)
+	}
+
+	return nil
+}
+
+func stop(ctx context.Context) error {
+	labels := map[string]string{
+		docker.LabelKeepAlive: "true",
+	}
+
+	removedIDs, err := docker.RemoveContainers(ctx, labels, nil)
+	printIDs(removedIDs)
+
+	if err != nil {
+		return fmt.Errorf("stop keep-alive containers, %w", err)
+	}
+
+	return nil
+}
+
+func printIDs(ids []string) {
+	for _, id := range ids {
+		fmt.Println(id)
+	}
+}
@@ -2,20 +2,86 @@ package containers
 
 import (
 	"os"
+	"slices"
 	"strconv"
+	"strings"
+	"sync"
 	"testing"
+
+	"github.com/amidgo/containers/internal/docker"
+)
+
+const (
+	BackendPostgres = "postgres"
+	BackendMinio    = "minio"
+	BackendRedis    = "redis"
 )
 
-func Disabled() bool {
-	env := os.Getenv("CONTAINERS_DISABLE_TESTING")
+const disableTestingEnvName = "CONTAINERS_DISABLE_TESTING"
 
-	disabled, _ := strconv.ParseBool(env)
+// Disabled reports whether testing with containers is disabled at all or for one of backends,
+// CONTAINERS_DISABLE_TESTING is either bool or comma separated list of backends, e.g. minio,redis.
+func Disabled(backends ...string) bool {
+	_, disabled := disabledBackend(backends)
 
 	return disabled
 }
 
-func SkipDisabled(t *testing.T) {
-	if Disabled() {
-		t.Skipf("test skipped because CONTAINERS_DISABLE_TESTING is SET to TRUE")
+func disabledBackend(backends []string) (backend string, disabled bool) {
+	env := os.Getenv(disableTestingEnvName)
+
+	disabledAll, err := strconv.ParseBool(env)
+	if err == nil {
+		return "", disabledAll
+	}
+
+	disabledBackends := strings.Split(env, ",")
+
+	for i := range disabledBackends {
+		disabledBackends[i] = strings.TrimSpace(disabledBackends[i])
+	}
+
+	for _, backend := range backends {
+		if slices.Contains(disabledBackends, backend) {
+			return backend, true
+		}
+	}
+
+	return "", false
+}
+
+func SkipDisabled(t *testing.T, backends ...string) {
+	t.Helper()
+
+	backend, disabled := disabledBackend(backends)
+
+	switch {
+	case !disabled:
+		return
+	case backend == "":
+		t.Skipf("test skipped because %s is SET to TRUE", disableTestingEnvName)
+	default:
+		t.Skipf("test skipped because %s backend is disabled by %s=%s", backend, disableTestingEnvName, os.Getenv(disableTestingEnvName))
 	}
 }
+
+// KeepAlive reports whether containers are kept running after tests to be reused by the next run.
+// The testcontainers reaper removes containers when go test exits, so CONTAINERS_KEEP_ALIVE is ignored
+// with a warning unless the reaper is disabled.
+func KeepAlive() bool {
+	env := os.Getenv("CONTAINERS_KEEP_ALIVE")
+
+	keepAlive, _ := strconv.ParseBool(env)
+	if keepAlive && !docker.ReaperDisabled() {
+		warnKeepAliveIgnored()
+
+		return false
+	}
+
+	return keepAlive
+}
+
+var warnKeepAliveIgnored = sync.OnceFunc(func() {
+	Logger().Warn("CONTAINERS_KEEP_ALIVE is ignored, the testcontainers reaper removes containers when go test exits, " +
+		"set TESTCONTAINERS_RYUK_DISABLED=true to keep them")
+})
@@ -13,3 +13,40 @@ func Test_Skipped(t *testing.T) {
 
 	t.Fatal("expected test is skipped")
 }
+
+func Test_Skipped_Backend(t *testing.T) {
+	t.Setenv("CONTAINERS_DISABLE_TESTING", "minio, redis")
+
+	if containers.Disabled(containers.BackendPostgres) {
+		t.Fatal("expected postgres is not disabled")
+	}
+
+	if !containers.Disabled(containers.BackendMinio) {
+		t.Fatal("expected minio is disabled")
+	}
+
+	containers.SkipDisabled(t, containers.BackendRedis)
+
+	t.Fatal("expected test is skipped")
+}
+
+func Test_KeepAlive(t *testing.T) {
+	t.Setenv("CONTAINERS_KEEP_ALIVE", "1")
+	t.Setenv("TESTCONTAINERS_RYUK_DISABLED", "true")
+
+	if !containers.KeepAlive() {
+		t.Fatal("expected keep alive enabled")
+	}
+
+	t.Setenv("TESTCONTAINERS_RYUK_DISABLED", "false")
+
+	if containers.KeepAlive() {
+		t.Fatal("expected keep alive ignored while reaper is enabled")
+	}
+
+	t.Setenv("CONTAINERS_KEEP_ALIVE", "")
+
+	if containers.KeepAlive() {
+		t.Fatal("expected keep alive disabled")
+	}
+}
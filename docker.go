@@ -0,0 +1,95 @@
+package containers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/amidgo/containers/internal/docker"
+)
+
+const dockerProbeTimeout = 10 * time.Second
+
+const onNoDockerEnvName = "CONTAINERS_ON_NO_DOCKER"
+
+const (
+	OnNoDockerFail = "fail"
+	OnNoDockerSkip = "skip"
+)
+
+// ErrDockerUnavailable is wrapped by errors of DockerAvailable, so callers creating containers can tell it apart.
+var ErrDockerUnavailable = errors.New("docker is not available")
+
+var dockerProbe = &dockerAvailability{}
+
+type dockerAvailability struct {
+	mu     sync.Mutex
+	probed bool
+	err    error
+}
+
+// DockerAvailable returns nil if the docker daemon responds, the daemon is probed once per process,
+// failures caused by done ctx are not remembered.
+func DockerAvailable(ctx context.Context) error {
+	dockerProbe.mu.Lock()
+	defer dockerProbe.mu.Unlock()
+
+	if dockerProbe.probed {
+		return dockerProbe.err
+	}
+
+	probeCtx, cancel := context.WithTimeout(ctx, dockerProbeTimeout)
+	defer cancel()
+
+	err := docker.Ping(probeCtx)
+	if err != nil {
+		err = fmt.Errorf("%w, %w", ErrDockerUnavailable, err)
+	}
+
+	if err != nil && ctx.Err() != nil {
+		return err
+	}
+
+	dockerProbe.probed = true
+	dockerProbe.err = err
+
+	return err
+}
+
+// RequireDocker skips or fails the test when docker is not available,
+// the policy is set by CONTAINERS_ON_NO_DOCKER=skip|fail, fail is default.
+func RequireDocker(t testing.TB) {
+	t.Helper()
+
+	err := DockerAvailable(context.Background())
+	if err == nil {
+		return
+	}
+
+	HandleNoDocker(t, err)
+}
+
+// HandleNoDocker skips or fails the test by CONTAINERS_ON_NO_DOCKER policy if err is caused by unavailable docker,
+// other errors are ignored. Reusables call it on failed enter, so tests of external containers never probe docker.
+func HandleNoDocker(t testing.TB, err error) {
+	t.Helper()
+
+	if !errors.Is(err, ErrDockerUnavailable) {
+		return
+	}
+
+	switch policy := os.Getenv(onNoDockerEnvName); policy {
+	case OnNoDockerSkip:
+		t.Skipf("test skipped because docker is not available, %s", err)
+	case "", OnNoDockerFail:
+		t.Fatalf("docker is not available, set %s=%s to skip tests, %s", onNoDockerEnvName, OnNoDockerSkip, err)
+	default:
+		t.Fatalf("docker is not available, invalid %s value %q, expected %s or %s, %s",
+			onNoDockerEnvName, policy, OnNoDockerSkip, OnNoDockerFail, err,
+		)
+	}
+}
@@ -0,0 +1,62 @@
+package containers_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/amidgo/containers"
+)
+
+type noDockerTB struct {
+	testing.TB
+
+	skipped bool
+	fatal   bool
+}
+
+func (n *noDockerTB) Helper() {}
+
+func (n *noDockerTB) Skipf(string, ...any) {
+	n.skipped = true
+}
+
+func (n *noDockerTB) Fatalf(string, ...any) {
+	n.fatal = true
+}
+
+func Test_HandleNoDocker(t *testing.T) {
+	errNoDocker := fmt.Errorf("create new container, %w", containers.ErrDockerUnavailable)
+
+	cases := []struct {
+		name    string
+		policy  string
+		err     error
+		skipped bool
+		fatal   bool
+	}{
+		{name: "default", policy: "", err: errNoDocker, fatal: true},
+		{name: "fail", policy: containers.OnNoDockerFail, err: errNoDocker, fatal: true},
+		{name: "skip", policy: containers.OnNoDockerSkip, err: errNoDocker, skipped: true},
+		{name: "invalid", policy: "ignore", err: errNoDocker, fatal: true},
+		{name: "other error", policy: containers.OnNoDockerSkip, err: errors.New("migrations failed")},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("CONTAINERS_ON_NO_DOCKER", tc.policy)
+
+			tb := &noDockerTB{TB: t}
+
+			containers.HandleNoDocker(tb, tc.err)
+
+			if tb.skipped != tc.skipped {
+				t.Fatalf("expected skipped %t, actual %t", tc.skipped, tb.skipped)
+			}
+
+			if tb.fatal != tc.fatal {
+				t.Fatalf("expected fatal %t, actual %t", tc.fatal, tb.fatal)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/testcontainers/testcontainers-go"
+)
+
+func RemoveContainer(ctx context.Context, containerID string) error {
+	cli, err := testcontainers.NewDockerClientWithOpts(ctx)
+	if err != nil {
+		return fmt.Errorf("create docker client, %w", err)
+	}
+
+	defer cli.Close()
+
+	err = cli.ContainerRemove(ctx, containerID, container.RemoveOptions{
+		RemoveVolumes: true,
+		Force:         true,
+	})
+	if err != nil {
+		return fmt.Errorf("remove container %s, %w", containerID, err)
+	}
+
+	return nil
+}
+
+func Ping(ctx context.Context) error {
+	cli, err := testcontainers.NewDockerClientWithOpts(ctx)
+	if err != nil {
+		return fmt.Errorf("create docker client, %w", err)
+	}
+
+	defer cli.Close()
+
+	_, err = cli.Ping(ctx)
+	if err != nil {
+		return fmt.Errorf("ping docker daemon, %w", err)
+	}
+
+	return nil
+}
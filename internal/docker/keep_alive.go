@@ -0,0 +1,57 @@
+package docker
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/testcontainers/testcontainers-go"
+)
+
+const LabelKeepAlive = LabelLibrary + ".keep-alive"
+
+// KeepAliveName is stable between runs for the same backend and config.
+func KeepAliveName(backend string, config ...string) string {
+	hash := sha256.Sum256([]byte(strings.Join(config, "\x00")))
+
+	return "amidgo-containers-" + backend + "-" + hex.EncodeToString(hash[:6])
+}
+
+// WithKeepAlive reuses the container with the name if it exists and labels it for the stop command,
+// the container outlives the test session only if the reaper is disabled.
+func WithKeepAlive(name string) testcontainers.CustomizeRequestOption {
+	return func(req *testcontainers.GenericContainerRequest) error {
+		req.Name = name
+		req.Reuse = true
+
+		configModifier := req.ConfigModifier
+
+		req.ConfigModifier = func(cfg *container.Config) {
+			if configModifier != nil {
+				configModifier(cfg)
+			}
+
+			if cfg.Labels == nil {
+				cfg.Labels = make(map[string]string)
+			}
+
+			cfg.Labels[LabelKeepAlive] = "true"
+		}
+
+		return nil
+	}
+}
+
+// ReaperDisabled reports whether the testcontainers reaper is disabled by TESTCONTAINERS_RYUK_DISABLED
+// or by ryuk.disabled of the testcontainers properties file.
+func ReaperDisabled() bool {
+	disabled, err := strconv.ParseBool(os.Getenv("TESTCONTAINERS_RYUK_DISABLED"))
+	if err == nil {
+		return disabled
+	}
+
+	return testcontainers.ReadConfig().Config.RyukDisabled
+}
@@ -0,0 +1,108 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"strconv"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/testcontainers/testcontainers-go"
+)
+
+const (
+	LabelLibrary    = "com.github.amidgo.containers"
+	LabelSession    = LabelLibrary + ".session"
+	LabelSessionPID = LabelLibrary + ".session-pid"
+	LabelHost       = LabelLibrary + ".host"
+)
+
+// SessionLabels marks containers started by the library, session is shared by all packages of one go test invocation.
+// Session pid is the pid of the go test command, the container is stale once it exits.
+func SessionLabels() map[string]string {
+	host, _ := os.Hostname()
+
+	return map[string]string{
+		LabelLibrary:    "true",
+		LabelSession:    testcontainers.SessionID(),
+		LabelSessionPID: strconv.Itoa(os.Getppid()),
+		LabelHost:       host,
+	}
+}
+
+// Stale reports whether the session which started the container is over,
+// containers started on another host are never stale, containers without session pid always are.
+func Stale(labels map[string]string) bool {
+	pid, err := strconv.Atoi(labels[LabelSessionPID])
+	if err != nil {
+		return true
+	}
+
+	host, _ := os.Hostname()
+	if labels[LabelHost] != host {
+		return false
+	}
+
+	return !processAlive(pid)
+}
+
+func WithSessionLabels() testcontainers.CustomizeRequestOption {
+	return func(req *testcontainers.GenericContainerRequest) error {
+		if req.Labels == nil {
+			req.Labels = make(map[string]string)
+		}
+
+		maps.Copy(req.Labels, SessionLabels())
+
+		return nil
+	}
+}
+
+// RemoveContainers removes all containers, running or not, which have every label of labels
+// and are accepted by filter, nil filter accepts every container.
+func RemoveContainers(
+	ctx context.Context,
+	labels map[string]string,
+	filter func(labels map[string]string) bool,
+) (removedIDs []string, err error) {
+	cli, err := testcontainers.NewDockerClientWithOpts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("create docker client, %w", err)
+	}
+
+	defer cli.Close()
+
+	args := filters.NewArgs()
+
+	for key, value := range labels {
+		args.Add("label", key+"="+value)
+	}
+
+	cnts, err := cli.ContainerList(ctx, container.ListOptions{
+		All:     true,
+		Filters: args,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list containers, %w", err)
+	}
+
+	for _, cnt := range cnts {
+		if filter != nil && !filter(cnt.Labels) {
+			continue
+		}
+
+		err = cli.ContainerRemove(ctx, cnt.ID, container.RemoveOptions{
+			RemoveVolumes: true,
+			Force:         true,
+		})
+		if err != nil {
+			return removedIDs, fmt.Errorf("remove container %s, %w", cnt.ID, err)
+		}
+
+		removedIDs = append(removedIDs, cnt.ID)
+	}
+
+	return removedIDs, nil
+}
@@ -0,0 +1,42 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// ContainerLogs returns stdout and stderr of the container written after since.
+func ContainerLogs(ctx context.Context, containerID string, since time.Time) ([]byte, error) {
+	cli, err := testcontainers.NewDockerClientWithOpts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("create docker client, %w", err)
+	}
+
+	defer cli.Close()
+
+	rc, err := cli.ContainerLogs(ctx, containerID, container.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Since:      since.Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get logs of container %s, %w", containerID, err)
+	}
+
+	defer rc.Close()
+
+	var buf bytes.Buffer
+
+	_, err = stdcopy.StdCopy(&buf, &buf, rc)
+	if err != nil {
+		return nil, fmt.Errorf("read logs of container %s, %w", containerID, err)
+	}
+
+	return buf.Bytes(), nil
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"runtime/debug"
+	"strconv"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -14,24 +15,45 @@ import (
 	"golang.org/x/sync/errgroup"
 )
 
-var daemon = containers.RunReusableDaemon(context.Background(), time.Second, ccf())
+var daemon = containers.RunSharedReusableDaemon(
+	context.Background(),
+	time.Second,
+	ccf(),
+	containers.Sharing[noopContainer]{Key: "internal/testing/reuse", Sharer: noopSharer{}},
+)
+
+type noopContainer struct{}
+
+func (noopContainer) Terminate(context.Context) error {
+	return nil
+}
+
+type noopSharer struct{}
+
+func (noopSharer) Share(context.Context, noopContainer) ([]byte, error) {
+	return []byte(strconv.Itoa(os.Getpid())), nil
+}
+
+func (noopSharer) Attach(context.Context, []byte) (noopContainer, error) {
+	return noopContainer{}, nil
+}
 
-func ccf() containers.CreateContainerFunc {
+func ccf() containers.CreateContainerFunc[noopContainer] {
 	counter := atomic.Int64{}
 	errDoubleCcfCall := errors.New("double call of create container func")
 
-	return containers.CreateContainerFunc(func(ctx context.Context) (any, error) {
+	return containers.CreateContainerFunc[noopContainer](func(ctx context.Context) (noopContainer, error) {
 		defer func() { counter.Add(1) }()
 
 		switch counter.Load() {
 		case 0:
 		case 1:
-			return 0, fmt.Errorf("%s, %d, %w", debug.Stack(), os.Getpid(), errDoubleCcfCall)
+			return noopContainer{}, fmt.Errorf("%s, %d, %w", debug.Stack(), os.Getpid(), errDoubleCcfCall)
 		default:
-			return 0, fmt.Errorf("%s, %d", errDoubleCcfCall, os.Getpid())
+			return noopContainer{}, fmt.Errorf("%s, %d", errDoubleCcfCall, os.Getpid())
 		}
 
-		return 0, nil
+		return noopContainer{}, nil
 	})
 }
 
@@ -102,12 +124,12 @@ func simpleEnterAndExit(
 	ctx := context.Background()
 	defer notify()
 
-	_, err := daemon.Enter(ctx)
+	lease, err := daemon.Enter(ctx)
 	if err != nil {
 		return fmt.Errorf("enter to daemon, expected no error, actual %w", err)
 	}
 
-	go daemon.Exit()
+	go lease.Release()
 
 	<-time.After(waitDuration / 2)
 
@@ -123,12 +145,12 @@ func awaitNotifyEnterAndExit(
 	ctx, cancel := context.WithCancel(context.Background())
 	t.Cleanup(cancel)
 
-	_, err := daemon.Enter(ctx)
+	lease, err := daemon.Enter(ctx)
 	if err != nil {
 		return fmt.Errorf("enter to daemon, expected no error, actual %w", err)
 	}
 
-	daemon.Exit()
+	lease.Release()
 
 	return nil
 }
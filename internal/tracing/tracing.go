@@ -0,0 +1,27 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/amidgo/containers"
+
+// Start starts span with the global tracer provider, spans are not recorded until it is set by otel.SetTracerProvider.
+func Start(ctx context.Context, spanName string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, spanName, trace.WithAttributes(attrs...))
+}
+
+// End records err if it isn't nil and ends span, it is meant to be deferred with pointer to the named error.
+func End(span trace.Span, err *error) {
+	if *err != nil {
+		span.RecordError(*err)
+		span.SetStatus(codes.Error, (*err).Error())
+	}
+
+	span.End()
+}
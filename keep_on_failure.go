@@ -0,0 +1,95 @@
+package containers
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+)
+
+const keepOnFailureEnvName = "CONTAINERS_KEEP_ON_FAILURE"
+
+const keepOnFailureTimeout = 10 * time.Second
+
+// KeepOnFailure reports whether containers used by failed tests are left running for debugging.
+func KeepOnFailure() bool {
+	env := os.Getenv(keepOnFailureEnvName)
+
+	keep, _ := strconv.ParseBool(env)
+
+	return keep
+}
+
+// Keeper is implemented by containers which can be left running, Terminate of the kept container does nothing.
+type Keeper interface {
+	Keep()
+}
+
+// keepable is implemented by wrappers, e.g. Lease, which are Keepers only if the wrapped container is.
+type keepable interface {
+	keepable() bool
+}
+
+func asKeeper(cnt any) (Keeper, bool) {
+	wrapper, ok := cnt.(keepable)
+	if ok && !wrapper.keepable() {
+		return nil, false
+	}
+
+	keeper, ok := cnt.(Keeper)
+
+	return keeper, ok
+}
+
+// KeepOnFailureCleanup keeps cnt running if the test fails and KeepOnFailure is enabled, cnt may be a Lease,
+// kept container of the lease isn't handed out to other tests.
+// command returns a ready to paste command connecting to the state left by the test, it is logged to the test.
+// The func must be called after term of the container is registered in t.Cleanup.
+func KeepOnFailureCleanup(t testing.TB, cnt any, command func(ctx context.Context) (string, error)) {
+	if !KeepOnFailure() {
+		return
+	}
+
+	t.Cleanup(func() {
+		if !t.Failed() {
+			return
+		}
+
+		keeper, ok := asKeeper(cnt)
+		if !ok {
+			t.Logf("container %T can not be kept after failure", cnt)
+
+			return
+		}
+
+		keeper.Keep()
+
+		ctx, cancel := context.WithTimeout(context.Background(), keepOnFailureTimeout)
+		defer cancel()
+
+		cmd, err := command(ctx)
+		if err != nil {
+			t.Logf("container is kept after failure, build connection command, %s", err)
+
+			return
+		}
+
+		t.Logf("container is kept after failure, connect with\n\t%s", cmd)
+	})
+}
+
+// KeptOnFailure reports whether ctx belongs to a failed test, see ContextWithTest, and KeepOnFailure is enabled,
+// per test state, e.g. a schema or a database, is not removed in this case.
+func KeptOnFailure(ctx context.Context) bool {
+	if !KeepOnFailure() {
+		return false
+	}
+
+	t, ok := ctx.Value(testKey{}).(testing.TB)
+	if !ok {
+		return false
+	}
+
+	return t.Failed()
+}
@@ -0,0 +1,161 @@
+package containers_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/amidgo/containers"
+)
+
+type failedTB struct {
+	testing.TB
+
+	failed   bool
+	cleanups []func()
+	logs     []string
+}
+
+func (f *failedTB) Failed() bool {
+	return f.failed
+}
+
+func (f *failedTB) Cleanup(cleanup func()) {
+	f.cleanups = append(f.cleanups, cleanup)
+}
+
+func (f *failedTB) Logf(format string, args ...any) {
+	f.logs = append(f.logs, fmt.Sprintf(format, args...))
+}
+
+func (f *failedTB) runCleanups() {
+	for i := len(f.cleanups) - 1; i >= 0; i-- {
+		f.cleanups[i]()
+	}
+}
+
+type keptContainer struct {
+	kept bool
+}
+
+func (k *keptContainer) Keep() {
+	k.kept = true
+}
+
+func connectCommand(context.Context) (string, error) {
+	return "psql 'postgres://localhost'", nil
+}
+
+func Test_KeepOnFailureCleanup(t *testing.T) {
+	t.Setenv("CONTAINERS_KEEP_ON_FAILURE", "1")
+
+	tb := &failedTB{TB: t, failed: true}
+	cnt := &keptContainer{}
+
+	containers.KeepOnFailureCleanup(tb, cnt, connectCommand)
+	tb.runCleanups()
+
+	if !cnt.kept {
+		t.Fatal("expected container of failed test is kept")
+	}
+
+	if len(tb.logs) != 1 || !strings.Contains(tb.logs[0], "psql 'postgres://localhost'") {
+		t.Fatalf("expected connection command logged, actual logs %q", tb.logs)
+	}
+}
+
+func Test_KeepOnFailureCleanup_Passed(t *testing.T) {
+	t.Setenv("CONTAINERS_KEEP_ON_FAILURE", "1")
+
+	tb := &failedTB{TB: t}
+	cnt := &keptContainer{}
+
+	containers.KeepOnFailureCleanup(tb, cnt, connectCommand)
+	tb.runCleanups()
+
+	if cnt.kept {
+		t.Fatal("expected container of passed test is not kept")
+	}
+}
+
+func Test_KeepOnFailureCleanup_Disabled(t *testing.T) {
+	t.Setenv("CONTAINERS_KEEP_ON_FAILURE", "")
+
+	tb := &failedTB{TB: t, failed: true}
+	cnt := &keptContainer{}
+
+	containers.KeepOnFailureCleanup(tb, cnt, connectCommand)
+	tb.runCleanups()
+
+	if cnt.kept {
+		t.Fatal("expected container is not kept when keep on failure is disabled")
+	}
+}
+
+type keptTerminater struct {
+	id         int64
+	kept       atomic.Bool
+	terminated atomic.Bool
+}
+
+func (k *keptTerminater) Keep() {
+	k.kept.Store(true)
+}
+
+func (k *keptTerminater) Terminate(context.Context) error {
+	if !k.kept.Load() {
+		k.terminated.Store(true)
+	}
+
+	return nil
+}
+
+func Test_KeepOnFailureCleanup_Lease(t *testing.T) {
+	t.Setenv("CONTAINERS_KEEP_ON_FAILURE", "1")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	created := atomic.Int64{}
+
+	ccf := containers.CreateContainerFunc[*keptTerminater](func(context.Context) (*keptTerminater, error) {
+		return &keptTerminater{id: created.Add(1)}, nil
+	})
+
+	daemon := containers.RunReusableDaemon(ctx, time.Minute, ccf)
+
+	lease, err := daemon.Enter(ctx)
+	if err != nil {
+		t.Fatalf("enter, expected no error, actual %s", err)
+	}
+
+	kept := lease.Container()
+
+	tb := &failedTB{TB: t, failed: true}
+
+	tb.Cleanup(lease.Release)
+	containers.KeepOnFailureCleanup(tb, lease, connectCommand)
+	tb.runCleanups()
+
+	if !kept.kept.Load() {
+		t.Fatal("expected container of failed test is kept")
+	}
+
+	next, err := daemon.Enter(ctx)
+	if err != nil {
+		t.Fatalf("enter after keep, expected no error, actual %s", err)
+	}
+
+	t.Cleanup(next.Release)
+
+	if next.Container() == kept {
+		t.Fatal("expected kept container is not handed out again")
+	}
+
+	if kept.terminated.Load() {
+		t.Fatal("expected kept container is not terminated")
+	}
+}
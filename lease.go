@@ -0,0 +1,170 @@
+package containers
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Lease is a single use of the reusable container acquired by ReusableDaemon.Enter.
+type Lease[T Terminater] struct {
+	daemon    *ReusableDaemon[T]
+	cnt       T
+	instance  *reusableInstance[T]
+	info      LeaseInfo
+	warmup    bool
+	blockedAt time.Time
+	enteredAt time.Time
+	released  atomic.Bool
+	kept      atomic.Bool
+}
+
+type LeaseInfo struct {
+	TestName   string
+	Caller     string
+	AcquiredAt time.Time
+	// SlotWait is time spent waiting for a free slot of the container limited by WithMaxActiveUsers.
+	SlotWait time.Duration
+	Stack    []byte
+}
+
+func (i LeaseInfo) String() string {
+	testName := i.TestName
+	if testName == "" {
+		testName = "<unknown test>"
+	}
+
+	return fmt.Sprintf("%s, acquired at %s %s ago", testName, i.Caller, time.Since(i.AcquiredAt).Round(time.Millisecond))
+}
+
+func newLease[T Terminater](ctx context.Context, daemon *ReusableDaemon[T]) *Lease[T] {
+	return &Lease[T]{
+		daemon: daemon,
+		info: LeaseInfo{
+			TestName:   testNameFromContext(ctx),
+			Caller:     leaseCaller(),
+			AcquiredAt: time.Now(),
+			Stack:      debug.Stack(),
+		},
+	}
+}
+
+func (l *Lease[T]) Container() T {
+	return l.cnt
+}
+
+func (l *Lease[T]) Info() LeaseInfo {
+	return l.info
+}
+
+// Release returns the container to the daemon, calls after the first one are reported and ignored.
+func (l *Lease[T]) Release() {
+	if !l.released.CompareAndSwap(false, true) {
+		l.daemon.logger().Warn("reusable container lease released twice",
+			"lease", l.info.String(),
+			"released_again_at", string(debug.Stack()),
+			"acquired_at", string(l.info.Stack),
+		)
+
+		return
+	}
+
+	l.daemon.exit(l)
+}
+
+// Keep keeps the container if it is a Keeper and retires it on Release,
+// so the daemon hands out new container and doesn't terminate the kept one.
+func (l *Lease[T]) Keep() {
+	keeper, ok := any(l.cnt).(Keeper)
+	if !ok {
+		return
+	}
+
+	keeper.Keep()
+
+	l.kept.Store(true)
+}
+
+func (l *Lease[T]) keepable() bool {
+	_, ok := any(l.cnt).(Keeper)
+
+	return ok
+}
+
+func (l *Lease[T]) reportNotReleased() {
+	l.daemon.logger().Warn("reusable container lease is not released before daemon stop",
+		"lease", l.info.String(),
+		"acquired_at", string(l.info.Stack),
+	)
+}
+
+type testNameKey struct{}
+
+// ContextWithTestName marks ctx with the name of the test, leases acquired with the ctx are reported with it.
+func ContextWithTestName(ctx context.Context, testName string) context.Context {
+	return context.WithValue(ctx, testNameKey{}, testName)
+}
+
+func testNameFromContext(ctx context.Context) string {
+	testName, _ := ctx.Value(testNameKey{}).(string)
+
+	return testName
+}
+
+type testKey struct{}
+
+// ContextWithTest marks ctx with the test name and logs waits for a free container slot to the test.
+func ContextWithTest(ctx context.Context, t testing.TB) context.Context {
+	return context.WithValue(ContextWithTestName(ctx, t.Name()), testKey{}, t)
+}
+
+func logSlotWait(ctx context.Context, info LeaseInfo) {
+	if info.SlotWait <= 0 {
+		return
+	}
+
+	t, ok := ctx.Value(testKey{}).(testing.TB)
+	if !ok {
+		return
+	}
+
+	t.Logf("waited %s for a free slot of reusable container", info.SlotWait)
+}
+
+const modulePath = "github.com/amidgo/containers"
+
+// leaseCaller returns the first frame outside of the library packages.
+func leaseCaller() string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+
+	frames := runtime.CallersFrames(pcs[:n])
+
+	for {
+		frame, more := frames.Next()
+
+		if !isLibraryFunction(frame.Function) {
+			return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+
+		if !more {
+			return "<unknown caller>"
+		}
+	}
+}
+
+func isLibraryFunction(function string) bool {
+	if !strings.HasPrefix(function, modulePath) {
+		return false
+	}
+
+	pkgStart := strings.LastIndex(function, "/") + 1
+	pkgEnd := pkgStart + strings.Index(function[pkgStart:], ".")
+
+	return !strings.HasSuffix(function[:pkgEnd], "_test")
+}
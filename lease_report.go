@@ -0,0 +1,23 @@
+package containers
+
+import (
+	"fmt"
+	"io"
+)
+
+type LeaseReporter interface {
+	Leases() []LeaseInfo
+}
+
+// ReportLeases writes every lease which is still held, it is meant to be called from TestMain after m.Run.
+func ReportLeases(w io.Writer, reporters ...LeaseReporter) (leaked int) {
+	for _, reporter := range reporters {
+		for _, info := range reporter.Leases() {
+			fmt.Fprintf(w, "reusable container lease is not released, %s\n", info)
+
+			leaked++
+		}
+	}
+
+	return leaked
+}
@@ -0,0 +1,136 @@
+package containers_test
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amidgo/containers"
+)
+
+func Test_Lease_Release_Twice(t *testing.T) {
+	logs := &bytes.Buffer{}
+
+	log.SetOutput(logs)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	cnt := newMockTerminater(t)
+
+	ccf := containers.CreateContainerFunc[*mockTerminater](func(context.Context) (*mockTerminater, error) {
+		return cnt, nil
+	})
+
+	daemon := containers.RunReusableDaemon(ctx, time.Millisecond, ccf)
+
+	first, err := daemon.Enter(ctx)
+	if err != nil {
+		t.Fatalf("first enter, expected no error, actual %s", err)
+	}
+
+	second, err := daemon.Enter(ctx)
+	if err != nil {
+		t.Fatalf("second enter, expected no error, actual %s", err)
+	}
+
+	first.Release()
+	first.Release()
+
+	<-time.After(time.Millisecond * 50)
+
+	if cnt.terminated.Load() {
+		t.Fatal("container terminated while second lease is not released")
+	}
+
+	if !strings.Contains(logs.String(), "lease released twice") {
+		t.Fatalf("double release is not reported, logs: %s", logs)
+	}
+
+	if !strings.Contains(logs.String(), "Test_Lease_Release_Twice") {
+		t.Fatalf("double release report doesn't contain acquire stack, logs: %s", logs)
+	}
+
+	second.Release()
+
+	awaitTerminated(t, cnt, time.Second)
+}
+
+func Test_Lease_Not_Released(t *testing.T) {
+	logs := &bytes.Buffer{}
+
+	log.SetOutput(logs)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	ccf := containers.CreateContainerFunc[*mockTerminater](func(context.Context) (*mockTerminater, error) {
+		return newMockTerminater(t), nil
+	})
+
+	daemon := containers.RunReusableDaemon(ctx, time.Minute, ccf)
+
+	_, err := daemon.Enter(ctx)
+	if err != nil {
+		t.Fatalf("enter, expected no error, actual %s", err)
+	}
+
+	cancel()
+	<-daemon.Done()
+
+	if !strings.Contains(logs.String(), "lease is not released") {
+		t.Fatalf("not released lease is not reported, logs: %s", logs)
+	}
+
+	if !strings.Contains(logs.String(), "Test_Lease_Not_Released") {
+		t.Fatalf("not released lease report doesn't contain acquire stack, logs: %s", logs)
+	}
+}
+
+func Test_ReportLeases(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	ccf := containers.CreateContainerFunc[*mockTerminater](func(context.Context) (*mockTerminater, error) {
+		return newMockTerminater(t), nil
+	})
+
+	daemon := containers.RunReusableDaemon(ctx, time.Minute, ccf)
+
+	released, err := daemon.Enter(containers.ContextWithTestName(ctx, "Test_Released"))
+	if err != nil {
+		t.Fatalf("enter, expected no error, actual %s", err)
+	}
+
+	held, err := daemon.Enter(containers.ContextWithTestName(ctx, "Test_Held"))
+	if err != nil {
+		t.Fatalf("enter, expected no error, actual %s", err)
+	}
+
+	released.Release()
+
+	report := &bytes.Buffer{}
+
+	leaked := containers.ReportLeases(report, daemon)
+	if leaked != 1 {
+		t.Fatalf("expected 1 leaked lease, actual %d, report: %s", leaked, report)
+	}
+
+	if !strings.Contains(report.String(), "Test_Held") || strings.Contains(report.String(), "Test_Released") {
+		t.Fatalf("report doesn't contain only held lease test name, report: %s", report)
+	}
+
+	if !strings.Contains(report.String(), "lease_test.go") {
+		t.Fatalf("report doesn't contain caller, report: %s", report)
+	}
+	held.Release()
+
+	cancel()
+	<-daemon.Done()
+}
@@ -0,0 +1,189 @@
+package containers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+var globalLogger atomic.Pointer[slog.Logger]
+
+// SetLogger replaces the logger of the library, nil restores slog.Default,
+// secrets are redacted from records of any logger.
+func SetLogger(logger *slog.Logger) {
+	globalLogger.Store(logger)
+}
+
+// Logger returns the logger set by SetLogger or slog.Default.
+func Logger() *slog.Logger {
+	logger := globalLogger.Load()
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return RedactLogger(logger)
+}
+
+// TestLogger writes records to t.Log, so they are printed under the test.
+func TestLogger(t testing.TB) *slog.Logger {
+	return RedactLogger(slog.New(slog.NewTextHandler(testWriter{t: t}, &slog.HandlerOptions{Level: slog.LevelDebug})))
+}
+
+type testWriter struct {
+	t testing.TB
+}
+
+func (w testWriter) Write(p []byte) (int, error) {
+	w.t.Helper()
+	w.t.Log(strings.TrimSuffix(string(p), "\n"))
+
+	return len(p), nil
+}
+
+// LoggerFromContext returns TestLogger of the test set by ContextWithTest or the global logger.
+func LoggerFromContext(ctx context.Context) *slog.Logger {
+	t, ok := ctx.Value(testKey{}).(testing.TB)
+	if ok {
+		return TestLogger(t)
+	}
+
+	return Logger()
+}
+
+func RedactLogger(logger *slog.Logger) *slog.Logger {
+	_, redacted := logger.Handler().(redactHandler)
+	if redacted {
+		return logger
+	}
+
+	return slog.New(redactHandler{handler: logger.Handler()})
+}
+
+const redacted = "REDACTED"
+
+var secretKeyRegexp = regexp.MustCompile(`(?i)(password|passwd|secret|token|credential)`)
+
+// redactHandler replaces values of secret attributes and passwords of urls and connection strings.
+type redactHandler struct {
+	handler slog.Handler
+}
+
+func (h redactHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+func (h redactHandler) Handle(ctx context.Context, record slog.Record) error {
+	redactedRecord := slog.NewRecord(record.Time, record.Level, redactString(record.Message), record.PC)
+
+	record.Attrs(func(attr slog.Attr) bool {
+		redactedRecord.AddAttrs(redactAttr(attr))
+
+		return true
+	})
+
+	return h.handler.Handle(ctx, redactedRecord)
+}
+
+func (h redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redactedAttrs := make([]slog.Attr, len(attrs))
+
+	for i, attr := range attrs {
+		redactedAttrs[i] = redactAttr(attr)
+	}
+
+	return redactHandler{handler: h.handler.WithAttrs(redactedAttrs)}
+}
+
+func (h redactHandler) WithGroup(name string) slog.Handler {
+	return redactHandler{handler: h.handler.WithGroup(name)}
+}
+
+func redactAttr(attr slog.Attr) slog.Attr {
+	if secretKeyRegexp.MatchString(attr.Key) {
+		return slog.String(attr.Key, redacted)
+	}
+
+	value := attr.Value.Resolve()
+
+	switch value.Kind() {
+	case slog.KindGroup:
+		group := value.Group()
+		redactedGroup := make([]any, len(group))
+
+		for i, groupAttr := range group {
+			redactedGroup[i] = redactAttr(groupAttr)
+		}
+
+		return slog.Group(attr.Key, redactedGroup...)
+	case slog.KindString:
+		return slog.String(attr.Key, redactString(value.String()))
+	case slog.KindAny:
+		// errors, stringers and other values are formatted first, value is kept as is if there is nothing to hide
+		formatted := fmt.Sprint(value.Any())
+
+		redactedValue := redactString(formatted)
+		if redactedValue != formatted {
+			return slog.String(attr.Key, redactedValue)
+		}
+	}
+
+	return attr
+}
+
+var (
+	urlRegexp              = regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9+.-]*://[^\s"']+`)
+	keywordPasswordRegexp  = regexp.MustCompile(`(?i)\b(password|passwd)=\S+`)
+	userinfoPasswordRegexp = regexp.MustCompile(`(://[^:/@\s]+):[^@\s]+@`)
+)
+
+// redactString hides passwords in urls and key=value connection strings.
+func redactString(s string) string {
+	s = urlRegexp.ReplaceAllStringFunc(s, func(rawURL string) string {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			// formatted values may stick brackets to the url, so the password is hidden without parsing
+			return userinfoPasswordRegexp.ReplaceAllString(rawURL, "${1}:"+redacted+"@")
+		}
+
+		_, hasPassword := u.User.Password()
+		if hasPassword {
+			u.User = url.UserPassword(u.User.Username(), redacted)
+		}
+
+		query := u.Query()
+
+		for key := range query {
+			if secretKeyRegexp.MatchString(key) {
+				query.Set(key, redacted)
+			}
+		}
+
+		if len(query) > 0 {
+			u.RawQuery = query.Encode()
+		}
+
+		return u.String()
+	})
+
+	return keywordPasswordRegexp.ReplaceAllString(s, "${1}="+redacted)
+}
+
+// WithLogger sets the logger of the daemon instead of the global one.
+func WithLogger(logger *slog.Logger) ReusableDaemonOption {
+	return func(o *reusableDaemonOptions) {
+		o.logger = logger
+	}
+}
+
+func (d *ReusableDaemon[T]) logger() *slog.Logger {
+	if d.log == nil {
+		return Logger()
+	}
+
+	return RedactLogger(d.log)
+}
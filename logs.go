@@ -0,0 +1,90 @@
+package containers
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/amidgo/containers/internal/docker"
+)
+
+// ContainerIDer is implemented by containers running in docker.
+type ContainerIDer interface {
+	ContainerID() string
+}
+
+const (
+	dumpLogsEnvName      = "CONTAINERS_DUMP_LOGS"
+	dumpLogsLimitEnvName = "CONTAINERS_DUMP_LOGS_LIMIT"
+)
+
+const (
+	defaultDumpLogsLimit = 64 << 10
+	dumpLogsTimeout      = 10 * time.Second
+)
+
+// DumpLogsOnFailure attaches logs written by cnt after since to the output of t if the test fails,
+// cnt without ContainerIDer is ignored.
+// Dumping is disabled by CONTAINERS_DUMP_LOGS=false, CONTAINERS_DUMP_LOGS_LIMIT sets the limit in bytes,
+// only the last bytes of logs are attached when the limit is exceeded.
+func DumpLogsOnFailure(t testing.TB, since time.Time, cnt any) {
+	idr, ok := cnt.(ContainerIDer)
+	if !ok || !dumpLogsEnabled() {
+		return
+	}
+
+	t.Cleanup(func() {
+		if !t.Failed() {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), dumpLogsTimeout)
+		defer cancel()
+
+		containerID := idr.ContainerID()
+
+		logs, err := docker.ContainerLogs(ctx, containerID, since)
+		if err != nil {
+			t.Logf("dump container logs, %s", err)
+
+			return
+		}
+
+		t.Logf("logs of container %s since %s:\n%s", containerID, since.Format(time.RFC3339), truncateLogs(logs, dumpLogsLimit()))
+	})
+}
+
+func dumpLogsEnabled() bool {
+	env := os.Getenv(dumpLogsEnvName)
+	if env == "" {
+		return true
+	}
+
+	enabled, err := strconv.ParseBool(env)
+	if err != nil {
+		return true
+	}
+
+	return enabled
+}
+
+func dumpLogsLimit() int {
+	limit, err := strconv.Atoi(os.Getenv(dumpLogsLimitEnvName))
+	if err != nil || limit <= 0 {
+		return defaultDumpLogsLimit
+	}
+
+	return limit
+}
+
+func truncateLogs(logs []byte, limit int) string {
+	if len(logs) <= limit {
+		return string(logs)
+	}
+
+	truncated := len(logs) - limit
+
+	return "... " + strconv.Itoa(truncated) + " bytes truncated ...\n" + string(logs[truncated:])
+}
@@ -0,0 +1,94 @@
+package containers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+const mainTerminateTimeout = time.Minute
+
+// TermFunc adapts term funcs returned by Run and Reuse funcs to Terminater.
+type TermFunc func()
+
+func (f TermFunc) Terminate(context.Context) error {
+	f()
+
+	return nil
+}
+
+// Main is meant to be called from TestMain instead of os.Exit(m.Run()),
+// it runs tests, terminates every reusable and exits with the tests exit code.
+func Main(m *testing.M, reusables ...Terminater) {
+	os.Exit(RunMain(m, reusables...))
+}
+
+type TestsRunner interface {
+	Run() (code int)
+}
+
+// RunMain runs tests and terminates every reusable within a minute,
+// failed termination turns successful exit code into 1.
+func RunMain(m TestsRunner, reusables ...Terminater) (code int) {
+	code = m.Run()
+
+	reportMainLeases(reusables)
+
+	if summaryEnabled() {
+		defer runSummary.Print(os.Stderr)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), mainTerminateTimeout)
+	defer cancel()
+
+	err := terminateAll(ctx, reusables)
+	if err != nil {
+		Logger().Error("failed terminate reusables", "error", err)
+
+		if code == 0 {
+			code = 1
+		}
+	}
+
+	return code
+}
+
+func reportMainLeases(reusables []Terminater) {
+	reporters := make([]LeaseReporter, 0, len(reusables))
+
+	for _, reusable := range reusables {
+		reporter, ok := reusable.(LeaseReporter)
+		if ok {
+			reporters = append(reporters, reporter)
+		}
+	}
+
+	ReportLeases(os.Stderr, reporters...)
+}
+
+func terminateAll(ctx context.Context, reusables []Terminater) error {
+	errs := make([]error, len(reusables))
+
+	wg := sync.WaitGroup{}
+
+	for i, reusable := range reusables {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			err := reusable.Terminate(ctx)
+			if err != nil {
+				errs[i] = fmt.Errorf("terminate %T, %w", reusable, err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,65 @@
+package containers_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/amidgo/containers"
+)
+
+type testsRunner int
+
+func (r testsRunner) Run() int {
+	return int(r)
+}
+
+type terminaterFunc func(ctx context.Context) error
+
+func (f terminaterFunc) Terminate(ctx context.Context) error {
+	return f(ctx)
+}
+
+func Test_RunMain(t *testing.T) {
+	t.Parallel()
+
+	terminated := atomic.Int64{}
+
+	okTerminater := terminaterFunc(func(context.Context) error {
+		terminated.Add(1)
+
+		return nil
+	})
+
+	failedTerminater := terminaterFunc(func(context.Context) error {
+		terminated.Add(1)
+
+		return errors.New("failed")
+	})
+
+	termCalled := false
+
+	code := containers.RunMain(testsRunner(0), okTerminater, containers.TermFunc(func() { termCalled = true }))
+	if code != 0 {
+		t.Fatalf("expected code 0, actual %d", code)
+	}
+
+	if !termCalled {
+		t.Fatal("term func not called")
+	}
+
+	code = containers.RunMain(testsRunner(2), okTerminater)
+	if code != 2 {
+		t.Fatalf("expected tests code 2, actual %d", code)
+	}
+
+	code = containers.RunMain(testsRunner(0), okTerminater, failedTerminater)
+	if code != 1 {
+		t.Fatalf("expected code 1 after failed terminate, actual %d", code)
+	}
+
+	if terminated.Load() != 4 {
+		t.Fatalf("expected 4 terminate calls, actual %d", terminated.Load())
+	}
+}
@@ -2,6 +2,7 @@ package miniocontainer
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"testing"
 
@@ -21,7 +22,7 @@ func UseExternalForTestingConfig(
 	cfg *ExternalContainerConfig,
 	buckets ...Bucket,
 ) *minio.Client {
-	containers.SkipDisabled(t)
+	containers.SkipDisabled(t, containers.BackendMinio)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	t.Cleanup(cancel)
@@ -127,6 +128,13 @@ func (_ externalContainer) Terminate(context.Context) error {
 	return nil
 }
 
+// Keep does nothing, external minio is never terminated.
+func (externalContainer) Keep() {}
+
+func (e externalContainer) Credentials(context.Context) (endpoint, username, password string, err error) {
+	return e.endpoint, e.userName, e.password, nil
+}
+
 func (e externalContainer) Connect(ctx context.Context) (*minio.Client, error) {
 	return minio.New(e.endpoint,
 		&minio.Options{
@@ -135,3 +143,17 @@ func (e externalContainer) Connect(ctx context.Context) (*minio.Client, error) {
 		},
 	)
 }
+
+func (e externalContainer) Ping(ctx context.Context) error {
+	minioClient, err := e.Connect(ctx)
+	if err != nil {
+		return fmt.Errorf("connect to container, %w", err)
+	}
+
+	_, err = minioClient.ListBuckets(ctx)
+	if err != nil {
+		return fmt.Errorf("list buckets, %w", err)
+	}
+
+	return nil
+}
@@ -5,12 +5,13 @@ import (
 	"context"
 	"fmt"
 	"io/fs"
-	"log"
 	"path"
-	"testing"
 
+	"github.com/amidgo/containers"
+	"github.com/amidgo/containers/internal/tracing"
 	"github.com/minio/minio-go/v7"
 	minioclient "github.com/minio/minio-go/v7"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type Bucket struct {
@@ -38,9 +39,7 @@ func Files(fsys fs.FS) ([]File, error) {
 		return nil, fmt.Errorf("glob files by pattern, %w", err)
 	}
 
-	if testing.Testing() {
-		log.Printf("filePaths: %+v", filePaths)
-	}
+	containers.Logger().Debug("found files", "file_paths", filePaths)
 
 	files := make([]File, 0, len(filePaths))
 	err = fs.WalkDir(fsys, ".", func(filePath string, d fs.DirEntry, err error) error {
@@ -75,10 +74,13 @@ func Init(
 	cnt Container,
 	buckets ...Bucket,
 ) (minioClient *minio.Client, term func(), err error) {
+	ctx, span := tracing.Start(ctx, "miniocontainer.Init")
+	defer tracing.End(span, &err)
+
 	term = func() {
 		terminateErr := cnt.Terminate(ctx)
 		if terminateErr != nil {
-			log.Printf("failed to terminate minio container: %s", terminateErr)
+			containers.LoggerFromContext(ctx).Error("failed to terminate minio container", "error", terminateErr)
 		}
 	}
 
@@ -106,10 +108,13 @@ func insertBuckets(ctx context.Context, minioClient *minio.Client, buckets ...Bu
 	return nil
 }
 
-func insertSingleBucket(ctx context.Context, minioClient *minio.Client, bucket Bucket) error {
+func insertSingleBucket(ctx context.Context, minioClient *minio.Client, bucket Bucket) (err error) {
+	ctx, span := tracing.Start(ctx, "miniocontainer.insertBucket", attribute.String("bucket.name", bucket.Name))
+	defer tracing.End(span, &err)
+
 	makeBucketOpts := minioclient.MakeBucketOptions{}
 
-	err := minioClient.MakeBucket(ctx, bucket.Name, makeBucketOpts)
+	err = minioClient.MakeBucket(ctx, bucket.Name, makeBucketOpts)
 
 	switch {
 	case isBucketExistsError(err):
@@ -117,33 +122,46 @@ func insertSingleBucket(ctx context.Context, minioClient *minio.Client, bucket B
 		return fmt.Errorf("create bucket %s, %w", bucket.Name, err)
 	}
 
-	putObjectOpts := minioclient.PutObjectOptions{}
-
 	for _, file := range bucket.Files {
-		objectSize := int64(len(file.Content))
-
-		_, err = minioClient.PutObject(ctx,
-			bucket.Name,
-			file.Name,
-			bytes.NewBuffer(file.Content),
-			objectSize,
-			putObjectOpts,
-		)
+		err = putFile(ctx, minioClient, bucket.Name, file)
 		if err != nil {
-			return fmt.Errorf("put file %s into bucket %s, %w", file.Name, bucket.Name, err)
+			return err
 		}
 	}
 
 	return nil
 }
 
+func putFile(ctx context.Context, minioClient *minio.Client, bucketName string, file File) (err error) {
+	ctx, span := tracing.Start(ctx, "miniocontainer.putFile",
+		attribute.String("bucket.name", bucketName),
+		attribute.String("object.name", file.Name),
+		attribute.Int("object.size", len(file.Content)),
+	)
+	defer tracing.End(span, &err)
+
+	putObjectOpts := minioclient.PutObjectOptions{}
+
+	objectSize := int64(len(file.Content))
+
+	_, err = minioClient.PutObject(ctx,
+		bucketName,
+		file.Name,
+		bytes.NewBuffer(file.Content),
+		objectSize,
+		putObjectOpts,
+	)
+	if err != nil {
+		return fmt.Errorf("put file %s into bucket %s, %w", file.Name, bucketName, err)
+	}
+
+	return nil
+}
+
 func isBucketExistsError(err error) bool {
 	resp := minio.ToErrorResponse(err)
 
-	if testing.Testing() {
-		log.Printf("resp: statusCode: %d, code: %s", resp.StatusCode, resp.Code)
-		log.Printf("err: %+v", err)
-	}
+	containers.Logger().Debug("make bucket response", "status_code", resp.StatusCode, "code", resp.Code, "error", err)
 
 	return resp.Code == "BucketAlreadyOwnedByYou"
 }
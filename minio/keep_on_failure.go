@@ -0,0 +1,49 @@
+package miniocontainer
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/amidgo/containers"
+)
+
+type credentialer interface {
+	Credentials(ctx context.Context) (endpoint, username, password string, err error)
+}
+
+// KeepOnFailure keeps cnt running if the test fails and containers.KeepOnFailure is enabled,
+// mc command setting an alias for the container is logged to the test.
+func KeepOnFailure(t *testing.T, cnt Container) {
+	if cnt == nil {
+		return
+	}
+
+	containers.KeepOnFailureCleanup(t, cnt, func(ctx context.Context) (string, error) {
+		return mcCommand(ctx, cnt)
+	})
+}
+
+// keepLeaseOnFailure is KeepOnFailure for the reused container, kept container is retired from the reusable,
+// so the next test gets new container.
+func keepLeaseOnFailure(t *testing.T, lease *containers.Lease[Container]) {
+	cnt := lease.Container()
+
+	containers.KeepOnFailureCleanup(t, lease, func(ctx context.Context) (string, error) {
+		return mcCommand(ctx, cnt)
+	})
+}
+
+func mcCommand(ctx context.Context, cnt Container) (string, error) {
+	cr, ok := cnt.(credentialer)
+	if !ok {
+		return "", fmt.Errorf("unsupported container type %T", cnt)
+	}
+
+	endpoint, username, password, err := cr.Credentials(ctx)
+	if err != nil {
+		return "", fmt.Errorf("get credentials, %w", err)
+	}
+
+	return fmt.Sprintf("mc alias set containers http://%s %s %s", endpoint, username, password), nil
+}
@@ -3,11 +3,13 @@ package miniocontainer
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/amidgo/containers"
+	"github.com/amidgo/containers/internal/tracing"
 	"github.com/minio/minio-go/v7"
 )
 
@@ -16,15 +18,24 @@ func ReuseForTesting(
 	reusable *Reusable,
 	buckets ...Bucket,
 ) *minio.Client {
-	containers.SkipDisabled(t)
+	containers.SkipDisabled(t, containers.BackendMinio)
 
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(containers.ContextWithTest(context.Background(), t))
 	t.Cleanup(cancel)
 
-	minioClient, term, err := Reuse(ctx, reusable, buckets...)
+	since := time.Now()
+
+	minioClient, lease, term, err := reusable.run(ctx, buckets...)
 	t.Cleanup(term)
 
+	if lease != nil {
+		containers.DumpLogsOnFailure(t, since, lease.Container())
+		keepLeaseOnFailure(t, lease)
+	}
+
 	if err != nil {
+		containers.HandleNoDocker(t, err)
+
 		t.Fatal(err)
 
 		return nil
@@ -38,7 +49,9 @@ func Reuse(
 	reusable *Reusable,
 	buckets ...Bucket,
 ) (minioClinet *minio.Client, term func(), err error) {
-	return reusable.run(ctx, buckets...)
+	minioClinet, _, term, err = reusable.run(ctx, buckets...)
+
+	return minioClinet, term, err
 }
 
 const defaultDuration = time.Second
@@ -51,13 +64,72 @@ func WithWaitDuration(waitDuration time.Duration) ReusableOption {
 	}
 }
 
+func WithSharing(key string, sharer containers.Sharer[Container]) ReusableOption {
+	return func(r *Reusable) {
+		r.sharing = &containers.Sharing[Container]{Key: key, Sharer: sharer}
+	}
+}
+
+func WithHealthCheck(interval time.Duration) ReusableOption {
+	return func(r *Reusable) {
+		r.daemonOpts = append(r.daemonOpts, containers.WithHealthCheck(interval))
+	}
+}
+
+func WithMaxUses(n int) ReusableOption {
+	return func(r *Reusable) {
+		r.daemonOpts = append(r.daemonOpts, containers.WithMaxUses(n))
+	}
+}
+
+func WithMaxLifetime(lifetime time.Duration) ReusableOption {
+	return func(r *Reusable) {
+		r.daemonOpts = append(r.daemonOpts, containers.WithMaxLifetime(lifetime))
+	}
+}
+
+func WithMaxActiveUsers(n int) ReusableOption {
+	return func(r *Reusable) {
+		r.daemonOpts = append(r.daemonOpts, containers.WithMaxActiveUsers(n))
+	}
+}
+
+func WithObserver(observer containers.Observer) ReusableOption {
+	return func(r *Reusable) {
+		r.daemonOpts = append(r.daemonOpts, containers.WithObserver(observer))
+	}
+}
+
+func WithLogger(logger *slog.Logger) ReusableOption {
+	return func(r *Reusable) {
+		r.daemonOpts = append(r.daemonOpts, containers.WithLogger(logger))
+	}
+}
+
+// WithPoolSize reuses up to size containers, every test is assigned to the least loaded one.
+func WithPoolSize(size int) ReusableOption {
+	return func(r *Reusable) {
+		r.poolSize = size
+	}
+}
+
 type Reusable struct {
 	ccf CreateContainerFunc
 
 	runDaemonOnce      sync.Once
-	daemon             *containers.ReusableDaemon
+	daemon             reusableDaemon
 	stopDaemon         context.CancelFunc
 	daemonWaitDuration time.Duration
+	daemonOpts         []containers.ReusableDaemonOption
+	poolSize           int
+	sharing            *containers.Sharing[Container]
+}
+
+type reusableDaemon interface {
+	Enter(ctx context.Context) (*containers.Lease[Container], error)
+	Warmup(ctx context.Context) error
+	Leases() []containers.LeaseInfo
+	Done() <-chan struct{}
 }
 
 func NewReusable(ccf CreateContainerFunc, opts ...ReusableOption) *Reusable {
@@ -73,7 +145,23 @@ func NewReusable(ccf CreateContainerFunc, opts ...ReusableOption) *Reusable {
 	return reusable
 }
 
+// Warmup creates the container in advance, it may be used with containers.Warmup.
+func (r *Reusable) Warmup(ctx context.Context) error {
+	r.runDaemonOnce.Do(r.runDaemon)
+
+	return r.daemon.Warmup(ctx)
+}
+
+// Leases returns leases which are not released yet, it may be used with containers.ReportLeases.
+func (r *Reusable) Leases() []containers.LeaseInfo {
+	r.runDaemonOnce.Do(r.runDaemon)
+
+	return r.daemon.Leases()
+}
+
 func (r *Reusable) Terminate(ctx context.Context) error {
+	r.runDaemonOnce.Do(r.runDaemon)
+
 	r.stopDaemon()
 
 	select {
@@ -84,53 +172,59 @@ func (r *Reusable) Terminate(ctx context.Context) error {
 	}
 }
 
-func (r *Reusable) run(ctx context.Context, buckets ...Bucket) (client *minio.Client, term func(), err error) {
+func (r *Reusable) run(
+	ctx context.Context,
+	buckets ...Bucket,
+) (client *minio.Client, lease *containers.Lease[Container], term func(), err error) {
 	r.runDaemonOnce.Do(r.runDaemon)
 
-	cnt, err := r.enter(ctx)
+	lease, err = r.enter(ctx)
 	if err != nil {
-		return nil, func() {}, fmt.Errorf("enter to reuse container, %w", err)
+		return nil, nil, func() {}, fmt.Errorf("enter to reuse container, %w", err)
 	}
 
-	client, term, err = r.reuse(ctx, cnt, buckets...)
+	client, term, err = r.reuse(ctx, lease, buckets...)
 	if err != nil {
-		return nil, term, fmt.Errorf("reuse container, %w", err)
+		return nil, lease, term, fmt.Errorf("reuse container, %w", err)
 	}
 
-	return client, term, nil
+	return client, lease, term, nil
 }
 
 func (r *Reusable) runDaemon() {
-	ccf := func(ctx context.Context) (any, error) {
-		return r.ccf(ctx)
-	}
-
 	ctx, cancel := context.WithCancel(context.Background())
 
-	r.daemon = containers.RunReusableDaemon(ctx,
-		r.daemonWaitDuration,
-		ccf,
-	)
+	ccf := containers.CreateContainerFunc[Container](r.ccf)
+
+	switch {
+	case r.poolSize > 1 && r.sharing != nil:
+		r.daemon = containers.RunSharedReusablePool(ctx, r.poolSize, r.daemonWaitDuration, ccf, *r.sharing, r.daemonOpts...)
+	case r.poolSize > 1:
+		r.daemon = containers.RunReusablePool(ctx, r.poolSize, r.daemonWaitDuration, ccf, r.daemonOpts...)
+	case r.sharing != nil:
+		r.daemon = containers.RunSharedReusableDaemon(ctx, r.daemonWaitDuration, ccf, *r.sharing, r.daemonOpts...)
+	default:
+		r.daemon = containers.RunReusableDaemon(ctx, r.daemonWaitDuration, ccf, r.daemonOpts...)
+	}
+
 	r.stopDaemon = cancel
 }
 
-func (r *Reusable) enter(ctx context.Context) (Container, error) {
-	cnt, err := r.daemon.Enter(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	return cnt.(Container), nil
+func (r *Reusable) enter(ctx context.Context) (*containers.Lease[Container], error) {
+	return r.daemon.Enter(ctx)
 }
 
 func (r *Reusable) reuse(
 	ctx context.Context,
-	cnt Container,
+	lease *containers.Lease[Container],
 	buckets ...Bucket,
 ) (minioClient *minio.Client, term func(), err error) {
-	term = r.daemon.Exit
+	ctx, span := tracing.Start(ctx, "miniocontainer.Reusable.reuse")
+	defer tracing.End(span, &err)
+
+	term = lease.Release
 
-	minioClient, err = cnt.Connect(ctx)
+	minioClient, err = lease.Container().Connect(ctx)
 	if err != nil {
 		return nil, term, fmt.Errorf("connect to container, %w", err)
 	}
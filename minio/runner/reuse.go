@@ -1,9 +1,23 @@
 package miniorunner
 
-import miniocontainer "github.com/amidgo/containers/minio"
+import (
+	"github.com/amidgo/containers"
+	miniocontainer "github.com/amidgo/containers/minio"
+)
 
-var reusable = miniocontainer.NewReusable(RunContainer(nil))
+var reusable = miniocontainer.NewReusable(RunContainer(nil), reusableOptions()...)
 
 func Reusable() *miniocontainer.Reusable {
 	return reusable
 }
+
+func reusableOptions() []miniocontainer.ReusableOption {
+	// keep-alive containers are already reattached by every process
+	if !containers.ReuseAcrossProcesses() || containers.KeepAlive() {
+		return nil
+	}
+
+	return []miniocontainer.ReusableOption{
+		miniocontainer.WithSharing("minio:"+containerMinioImage(nil), Sharer()),
+	}
+}
@@ -4,13 +4,19 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/amidgo/containers"
+	"github.com/amidgo/containers/internal/docker"
+	"github.com/amidgo/containers/internal/tracing"
 	miniocontainer "github.com/amidgo/containers/minio"
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/testcontainers/testcontainers-go"
 	miniocnt "github.com/testcontainers/testcontainers-go/modules/minio"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 func RunForTesting(
@@ -25,14 +31,27 @@ func RunForTestingConfig(
 	cfg *ContainerConfig,
 	buckets ...miniocontainer.Bucket,
 ) *minio.Client {
-	containers.SkipDisabled(t)
+	containers.SkipDisabled(t, containers.BackendMinio)
+	containers.RequireDocker(t)
 
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(containers.ContextWithTest(context.Background(), t))
 	t.Cleanup(cancel)
 
-	minioClient, term, err := RunConfig(ctx, cfg, buckets...)
+	since := time.Now()
+
+	cnt, err := runContainer(cfg, false)(ctx)
+	if err != nil {
+		t.Fatalf("run minio container with config, %s", err.Error())
+
+		return nil
+	}
+
+	minioClient, term, err := miniocontainer.Init(ctx, cnt, buckets...)
 	t.Cleanup(term)
 
+	containers.DumpLogsOnFailure(t, since, cnt)
+	miniocontainer.KeepOnFailure(t, cnt)
+
 	if err != nil {
 		t.Fatalf("run minio container with config, %s", err.Error())
 
@@ -54,7 +73,7 @@ func RunConfig(
 	cfg *ContainerConfig,
 	buckets ...miniocontainer.Bucket,
 ) (minioClient *minio.Client, term func(), err error) {
-	cnt, err := RunContainer(cfg)(ctx)
+	cnt, err := runContainer(cfg, false)(ctx)
 	if err != nil {
 		return nil, func() {}, fmt.Errorf("run container, %w", err)
 	}
@@ -103,29 +122,63 @@ func containerPassword(cfg *ContainerConfig) string {
 	return defaultPassword
 }
 
+// RunContainer keeps the container running after tests when containers.KeepAlive is enabled,
+// next runs reattach to it, keep-alive containers are removed by containers stop command.
 func RunContainer(cfg *ContainerConfig) miniocontainer.CreateContainerFunc {
-	return func(ctx context.Context) (miniocontainer.Container, error) {
+	return runContainer(cfg, containers.KeepAlive())
+}
+
+func runContainer(cfg *ContainerConfig, keepAlive bool) miniocontainer.CreateContainerFunc {
+	return func(ctx context.Context) (_ miniocontainer.Container, err error) {
+		err = containers.DockerAvailable(ctx)
+		if err != nil {
+			return nil, err
+		}
+
 		minioImage := containerMinioImage(cfg)
 		username := containerUsername(cfg)
 		password := containerPassword(cfg)
 
-		cnt, err := miniocnt.Run(ctx,
-			minioImage,
+		ctx, span := tracing.Start(ctx, "miniorunner.RunContainer", attribute.String("container.image", minioImage))
+		defer tracing.End(span, &err)
+
+		opts := []testcontainers.ContainerCustomizer{
 			miniocnt.WithUsername(username),
 			miniocnt.WithPassword(password),
-		)
+			docker.WithSessionLabels(),
+		}
+
+		if keepAlive {
+			name := docker.KeepAliveName("minio", minioImage, username, password)
+
+			opts = append(opts, docker.WithKeepAlive(name))
+		}
+
+		cnt, err := miniocnt.Run(ctx, minioImage, opts...)
 		if err != nil {
 			return nil, fmt.Errorf("run minio container, %w", err)
 		}
 
-		return container{
+		minioCnt := container{
 			minioContainer: cnt,
-		}, nil
+			keepAlive:      keepAlive,
+			kept:           &atomic.Bool{},
+			unregister:     func() {},
+		}
+
+		if !keepAlive {
+			minioCnt.unregister = containers.RegisterCleanup(cnt)
+		}
+
+		return minioCnt, nil
 	}
 }
 
 type container struct {
 	minioContainer *miniocnt.MinioContainer
+	keepAlive      bool
+	kept           *atomic.Bool
+	unregister     func()
 }
 
 func (c container) Connect(ctx context.Context) (*minio.Client, error) {
@@ -146,6 +199,48 @@ func (c container) Connect(ctx context.Context) (*minio.Client, error) {
 	return minioClient, nil
 }
 
+func (c container) Credentials(ctx context.Context) (endpoint, username, password string, err error) {
+	endpoint, err = c.minioContainer.ConnectionString(ctx)
+	if err != nil {
+		return "", "", "", fmt.Errorf("get endpoint, %w", err)
+	}
+
+	return endpoint, c.minioContainer.Username, c.minioContainer.Password, nil
+}
+
+func (c container) Keep() {
+	c.kept.Store(true)
+	c.unregister()
+}
+
 func (c container) Terminate(ctx context.Context) error {
+	if c.keepAlive || c.kept.Load() {
+		return nil
+	}
+
+	c.unregister()
+
 	return c.minioContainer.Terminate(ctx)
 }
+
+func (c container) ContainerID() string {
+	return c.minioContainer.GetContainerID()
+}
+
+func (c container) Ping(ctx context.Context) error {
+	minioClient, err := c.Connect(ctx)
+	if err != nil {
+		return err
+	}
+
+	return ping(ctx, minioClient)
+}
+
+func ping(ctx context.Context, minioClient *minio.Client) error {
+	_, err := minioClient.ListBuckets(ctx)
+	if err != nil {
+		return fmt.Errorf("list buckets, %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package miniorunner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/amidgo/containers"
+	"github.com/amidgo/containers/internal/docker"
+	miniocontainer "github.com/amidgo/containers/minio"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+func Sharer() containers.Sharer[miniocontainer.Container] {
+	return sharer{}
+}
+
+type sharedContainerState struct {
+	ContainerID string `json:"container_id"`
+	Endpoint    string `json:"endpoint"`
+	Username    string `json:"username"`
+	Password    string `json:"password"`
+}
+
+type sharer struct{}
+
+func (sharer) Share(ctx context.Context, cnt miniocontainer.Container) ([]byte, error) {
+	minioCnt, ok := cnt.(container)
+	if !ok {
+		return nil, fmt.Errorf("unsupported container type %T", cnt)
+	}
+
+	endpoint, err := minioCnt.minioContainer.ConnectionString(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get endpoint, %w", err)
+	}
+
+	state := sharedContainerState{
+		ContainerID: minioCnt.minioContainer.GetContainerID(),
+		Endpoint:    endpoint,
+		Username:    minioCnt.minioContainer.Username,
+		Password:    minioCnt.minioContainer.Password,
+	}
+
+	return json.Marshal(state)
+}
+
+func (sharer) Attach(_ context.Context, data []byte) (miniocontainer.Container, error) {
+	var state sharedContainerState
+
+	err := json.Unmarshal(data, &state)
+	if err != nil {
+		return nil, fmt.Errorf("decode shared container state, %w", err)
+	}
+
+	return sharedContainer{state: state}, nil
+}
+
+type sharedContainer struct {
+	state sharedContainerState
+}
+
+func (s sharedContainer) Connect(context.Context) (*minio.Client, error) {
+	opts := &minio.Options{
+		Creds: credentials.NewStaticV4(s.state.Username, s.state.Password, ""),
+	}
+
+	minioClient, err := minio.New(s.state.Endpoint, opts)
+	if err != nil {
+		return nil, fmt.Errorf("create minio client, %w", err)
+	}
+
+	return minioClient, nil
+}
+
+func (s sharedContainer) Terminate(ctx context.Context) error {
+	return docker.RemoveContainer(ctx, s.state.ContainerID)
+}
+
+func (s sharedContainer) Credentials(context.Context) (endpoint, username, password string, err error) {
+	return s.state.Endpoint, s.state.Username, s.state.Password, nil
+}
+
+func (s sharedContainer) ContainerID() string {
+	return s.state.ContainerID
+}
+
+func (s sharedContainer) Ping(ctx context.Context) error {
+	minioClient, err := s.Connect(ctx)
+	if err != nil {
+		return err
+	}
+
+	return ping(ctx, minioClient)
+}
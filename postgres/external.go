@@ -24,7 +24,7 @@ func UseExternalForTestingConfig(
 	migrations migrations.Migrations,
 	initialQueries ...migrations.Query,
 ) *sql.DB {
-	containers.SkipDisabled(t)
+	containers.SkipDisabled(t, containers.BackendPostgres)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	t.Cleanup(cancel)
@@ -136,10 +136,17 @@ func (_ externalContainer) Terminate(_ context.Context) error {
 	return nil
 }
 
-func (e externalContainer) Connect(_ context.Context, args ...string) (*sql.DB, error) {
+// Keep does nothing, external database is never terminated.
+func (externalContainer) Keep() {}
+
+func (e externalContainer) ConnectionString(_ context.Context, args ...string) (string, error) {
 	extraArgs := strings.Join(args, "&")
 
-	dataSourceName := e.connectionString + "?" + extraArgs
+	return e.connectionString + "?" + extraArgs, nil
+}
+
+func (e externalContainer) Connect(ctx context.Context, args ...string) (*sql.DB, error) {
+	dataSourceName, _ := e.ConnectionString(ctx, args...)
 
 	db, err := sql.Open(e.driverName, dataSourceName)
 	if err != nil {
@@ -148,3 +155,19 @@ func (e externalContainer) Connect(_ context.Context, args ...string) (*sql.DB,
 
 	return db, nil
 }
+
+func (e externalContainer) Ping(ctx context.Context) error {
+	db, err := e.Connect(ctx, "sslmode=disable")
+	if err != nil {
+		return err
+	}
+
+	defer db.Close()
+
+	err = db.PingContext(ctx)
+	if err != nil {
+		return fmt.Errorf("ping database, %w", err)
+	}
+
+	return nil
+}
@@ -4,8 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"log"
 
+	"github.com/amidgo/containers"
+	"github.com/amidgo/containers/internal/tracing"
 	"github.com/amidgo/containers/postgres/migrations"
 )
 
@@ -15,11 +16,14 @@ func Init(
 	mig migrations.Migrations,
 	initialQueries ...migrations.Query,
 ) (db *sql.DB, term func(), err error) {
+	ctx, span := tracing.Start(ctx, "postgrescontainer.Init")
+	defer tracing.End(span, &err)
+
 	// Clean up the container
 	term = func() {
 		terminateErr := pgCnt.Terminate(ctx)
 		if terminateErr != nil {
-			log.Printf("failed to terminate postgres container: %s", terminateErr)
+			containers.LoggerFromContext(ctx).Error("failed to terminate postgres container", "error", terminateErr)
 		}
 	}
 
@@ -33,7 +37,7 @@ func Init(
 
 		terminateErr := pgCnt.Terminate(ctx)
 		if terminateErr != nil {
-			log.Printf("failed to terminate postgres container: %s", terminateErr)
+			containers.LoggerFromContext(ctx).Error("failed to terminate postgres container", "error", terminateErr)
 		}
 	}
 
@@ -0,0 +1,67 @@
+package postgrescontainer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/amidgo/containers"
+)
+
+type connectionStringer interface {
+	ConnectionString(ctx context.Context, args ...string) (string, error)
+}
+
+// KeepOnFailure keeps pgCnt running if the test fails and containers.KeepOnFailure is enabled,
+// psql command connecting to the current database and schema of db is logged to the test.
+func KeepOnFailure(t *testing.T, pgCnt Container, db *sql.DB) {
+	if pgCnt == nil {
+		return
+	}
+
+	containers.KeepOnFailureCleanup(t, pgCnt, func(ctx context.Context) (string, error) {
+		return psqlCommand(ctx, pgCnt, db)
+	})
+}
+
+// keepLeaseOnFailure is KeepOnFailure for the reused container, kept container is retired from the reusable,
+// so the next test gets new container.
+func keepLeaseOnFailure(t *testing.T, lease *containers.Lease[Container], db *sql.DB) {
+	pgCnt := lease.Container()
+
+	containers.KeepOnFailureCleanup(t, lease, func(ctx context.Context) (string, error) {
+		return psqlCommand(ctx, pgCnt, db)
+	})
+}
+
+func psqlCommand(ctx context.Context, pgCnt Container, db *sql.DB) (string, error) {
+	cs, ok := pgCnt.(connectionStringer)
+	if !ok {
+		return "", fmt.Errorf("unsupported container type %T", pgCnt)
+	}
+
+	args := []string{"sslmode=disable"}
+
+	if db != nil {
+		var dbName, schemaName string
+
+		err := db.QueryRowContext(ctx, "SELECT current_database(), current_schema()").Scan(&dbName, &schemaName)
+		if err != nil {
+			return "", fmt.Errorf("get current database and schema, %w", err)
+		}
+
+		args = append(args,
+			"dbname="+url.QueryEscape(dbName),
+			"options="+url.QueryEscape("-csearch_path="+schemaName),
+		)
+	}
+
+	dataSourceName, err := cs.ConnectionString(ctx, args...)
+	if err != nil {
+		return "", fmt.Errorf("get connection string, %w", err)
+	}
+
+	return "psql '" + dataSourceName + "'", nil
+}
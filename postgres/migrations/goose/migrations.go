@@ -2,10 +2,13 @@ package goosemigrations
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"fmt"
 	"io/fs"
 
+	"github.com/amidgo/containers/internal/tracing"
 	"github.com/amidgo/containers/postgres/migrations"
 	"github.com/pressly/goose/v3"
 )
@@ -20,7 +23,10 @@ func New(fsys fs.FS) migrations.Migrations {
 	}
 }
 
-func (g gooseMigrations) Up(ctx context.Context, db *sql.DB) error {
+func (g gooseMigrations) Up(ctx context.Context, db *sql.DB) (err error) {
+	ctx, span := tracing.Start(ctx, "goosemigrations.Up")
+	defer tracing.End(span, &err)
+
 	gooseProvider, err := goose.NewProvider(goose.DialectPostgres, db, g.fsys)
 	if err != nil {
 		return fmt.Errorf("create provider, %w", err)
@@ -42,7 +48,10 @@ func (g gooseMigrations) Up(ctx context.Context, db *sql.DB) error {
 	return nil
 }
 
-func (g gooseMigrations) Down(ctx context.Context, db *sql.DB) error {
+func (g gooseMigrations) Down(ctx context.Context, db *sql.DB) (err error) {
+	ctx, span := tracing.Start(ctx, "goosemigrations.Down")
+	defer tracing.End(span, &err)
+
 	gooseProvider, err := goose.NewProvider(goose.DialectPostgres, db, g.fsys)
 	if err != nil {
 		return fmt.Errorf("create provider, %w", err)
@@ -63,3 +72,31 @@ func (g gooseMigrations) Down(ctx context.Context, db *sql.DB) error {
 
 	return nil
 }
+
+// Identity is a hash of paths and contents of all files of the migrations fs.
+func (g gooseMigrations) Identity() (string, error) {
+	hash := sha256.New()
+
+	err := fs.WalkDir(g.fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		data, err := fs.ReadFile(g.fsys, path)
+		if err != nil {
+			return err
+		}
+
+		hash.Write([]byte(path))
+		hash.Write([]byte{0})
+		hash.Write(data)
+		hash.Write([]byte{0})
+
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("walk migrations fs, %w", err)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
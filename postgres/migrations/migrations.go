@@ -21,3 +21,13 @@ func (nilMigrations) Up(context.Context, *sql.DB) error {
 func (nilMigrations) Down(context.Context, *sql.DB) error {
 	return nil
 }
+
+// Identifier is implemented by migrations which are able to tell whether they are the same as others,
+// equal identities mean migrations build the same database.
+type Identifier interface {
+	Identity() (string, error)
+}
+
+func (nilMigrations) Identity() (string, error) {
+	return "nil", nil
+}
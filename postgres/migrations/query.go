@@ -5,6 +5,10 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+
+	"github.com/amidgo/containers/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Query any
@@ -15,7 +19,15 @@ type sqlizer interface {
 
 var errInvalidQueryType = errors.New("invalid query type, expected string or sqlizer types")
 
-func ExecQuery(ctx context.Context, db *sql.DB, query Query) error {
+// Execer is implemented by *sql.DB, *sql.Conn and *sql.Tx.
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+func ExecQuery(ctx context.Context, db Execer, query Query) (err error) {
+	ctx, span := tracing.Start(ctx, "migrations.ExecQuery")
+	defer tracing.End(span, &err)
+
 	switch query := query.(type) {
 	case sqlizer:
 		return execSqlizer(ctx, db, query)
@@ -26,12 +38,14 @@ func ExecQuery(ctx context.Context, db *sql.DB, query Query) error {
 	}
 }
 
-func execSqlizer(ctx context.Context, db *sql.DB, query sqlizer) error {
+func execSqlizer(ctx context.Context, db Execer, query sqlizer) error {
 	sql, args, err := query.ToSql()
 	if err != nil {
 		return fmt.Errorf("exec sqlizer query, failed convert ToSql, %w", err)
 	}
 
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("db.query.text", sql))
+
 	_, err = db.ExecContext(ctx, sql, args...)
 	if err != nil {
 		return fmt.Errorf("exec %s query, %w", sql, err)
@@ -40,7 +54,9 @@ func execSqlizer(ctx context.Context, db *sql.DB, query sqlizer) error {
 	return nil
 }
 
-func execString(ctx context.Context, db *sql.DB, query string) error {
+func execString(ctx context.Context, db Execer, query string) error {
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("db.query.text", query))
+
 	_, err := db.ExecContext(ctx, query)
 	if err != nil {
 		return fmt.Errorf("exec %s query, %w", query, err)
@@ -0,0 +1,128 @@
+package postgrescontainer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/amidgo/containers/internal/tracing"
+	"github.com/amidgo/containers/postgres/migrations"
+)
+
+const gooseVersionTable = "goose_db_version"
+
+type ResetOptions struct {
+	// KeepTables are not truncated, names may be qualified by schema, e.g. public.currencies,
+	// kept tables referencing truncated ones are truncated by CASCADE anyway
+	KeepTables []string
+	// InitialQueries are executed after tables are truncated
+	InitialQueries []migrations.Query
+}
+
+func resetKeepTables(opts *ResetOptions) []string {
+	if opts == nil {
+		return nil
+	}
+
+	return opts.KeepTables
+}
+
+func resetInitialQueries(opts *ResetOptions) []migrations.Query {
+	if opts == nil {
+		return nil
+	}
+
+	return opts.InitialQueries
+}
+
+// Reset truncates all tables of schemas in search_path of db with RESTART IDENTITY CASCADE,
+// goose version table and opts.KeepTables are kept, then opts.InitialQueries are executed,
+// everything is done in a single transaction.
+func Reset(ctx context.Context, db *sql.DB, opts *ResetOptions) (err error) {
+	ctx, span := tracing.Start(ctx, "postgrescontainer.Reset")
+	defer tracing.End(span, &err)
+
+	tables, err := listResetTables(ctx, db, resetKeepTables(opts))
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction, %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if len(tables) > 0 {
+		query := fmt.Sprintf("TRUNCATE %s RESTART IDENTITY CASCADE", strings.Join(tables, ", "))
+
+		_, err = tx.ExecContext(ctx, query)
+		if err != nil {
+			return fmt.Errorf("truncate tables, %w", err)
+		}
+	}
+
+	for _, initialQuery := range resetInitialQueries(opts) {
+		err = migrations.ExecQuery(ctx, tx, initialQuery)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("commit reset, %w", err)
+	}
+
+	return nil
+}
+
+// listResetTables returns qualified names of tables in search_path except kept ones,
+// partitions are truncated with their parent tables.
+func listResetTables(ctx context.Context, db *sql.DB, keepTables []string) ([]string, error) {
+	const query = `SELECT n.nspname, c.relname
+FROM pg_class c
+JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE c.relkind IN ('r', 'p') AND NOT c.relispartition AND n.nspname = ANY (current_schemas(false))
+ORDER BY n.nspname, c.relname`
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("list tables, %w", err)
+	}
+
+	defer rows.Close()
+
+	var tables []string
+
+	for rows.Next() {
+		var schemaName, tableName string
+
+		err = rows.Scan(&schemaName, &tableName)
+		if err != nil {
+			return nil, fmt.Errorf("scan table name, %w", err)
+		}
+
+		if tableName == gooseVersionTable ||
+			slices.Contains(keepTables, tableName) ||
+			slices.Contains(keepTables, schemaName+"."+tableName) {
+			continue
+		}
+
+		tables = append(tables, qualifiedName(schemaName, tableName))
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("list tables, %w", err)
+	}
+
+	return tables, nil
+}
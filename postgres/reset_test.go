@@ -0,0 +1,60 @@
+package postgrescontainer_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	postgrescontainer "github.com/amidgo/containers/postgres"
+	"github.com/amidgo/containers/postgres/migrations"
+	goosemigrations "github.com/amidgo/containers/postgres/migrations/goose"
+	postgrescontainerrunner "github.com/amidgo/containers/postgres/runner"
+)
+
+func Test_Reset(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	db := postgrescontainer.ReuseForTesting(t,
+		postgrescontainerrunner.Reusable(),
+		goosemigrations.New(os.DirFS("./testdata/migrations")),
+		"INSERT INTO users (name) VALUES ('Dima'), ('amidman')",
+		"INSERT INTO animals (name) VALUES ('cat')",
+	)
+
+	err := postgrescontainer.Reset(ctx, db, &postgrescontainer.ResetOptions{
+		KeepTables:     []string{"animals"},
+		InitialQueries: []migrations.Query{"INSERT INTO users (name) VALUES ('amidgo')"},
+	})
+	if err != nil {
+		t.Fatalf("reset, expected no error, actual %s", err)
+	}
+
+	var id int
+
+	err = db.QueryRowContext(ctx, "SELECT id FROM users WHERE name = 'amidgo'").Scan(&id)
+	if err != nil {
+		t.Fatalf("get id of initial user, %s", err)
+	}
+
+	if id != 1 {
+		t.Fatalf("expected identity restarted, expected id 1, actual %d", id)
+	}
+
+	assertUsersCount(t, ctx, db, 1)
+	assertTableNotEmpty(t, ctx, db, "animals")
+	assertTableNotEmpty(t, ctx, db, "goose_db_version")
+}
+
+func assertTableNotEmpty(t *testing.T, ctx context.Context, db queryRower, table string) {
+	var count int
+
+	err := db.QueryRowContext(ctx, "SELECT count(*) FROM "+table).Scan(&count)
+	if err != nil {
+		t.Fatalf("count rows of %s, %s", table, err)
+	}
+
+	if count == 0 {
+		t.Fatalf("expected %s is not truncated", table)
+	}
+}
@@ -4,13 +4,15 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"math/rand/v2"
+	"log/slog"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/amidgo/containers"
+	"github.com/amidgo/containers/internal/tracing"
 	"github.com/amidgo/containers/postgres/migrations"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 func ReuseForTesting(
@@ -19,15 +21,24 @@ func ReuseForTesting(
 	mig migrations.Migrations,
 	initialQueries ...migrations.Query,
 ) *sql.DB {
-	containers.SkipDisabled(t)
+	containers.SkipDisabled(t, containers.BackendPostgres)
 
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(containers.ContextWithTest(context.Background(), t))
 	t.Cleanup(cancel)
 
-	db, term, err := Reuse(ctx, reuse, mig, initialQueries...)
+	since := time.Now()
+
+	db, lease, term, err := reuse.run(ctx, mig, initialQueries...)
 	t.Cleanup(term)
 
+	if lease != nil {
+		containers.DumpLogsOnFailure(t, since, lease.Container())
+		keepLeaseOnFailure(t, lease, db)
+	}
+
 	if err != nil {
+		containers.HandleNoDocker(t, err)
+
 		t.Fatalf("reuse container, err: %s", err)
 
 		return nil
@@ -42,7 +53,9 @@ func Reuse(
 	mig migrations.Migrations,
 	initialQueries ...migrations.Query,
 ) (db *sql.DB, term func(), err error) {
-	return reuse.run(ctx, mig, initialQueries...)
+	db, _, term, err = reuse.run(ctx, mig, initialQueries...)
+
+	return db, term, err
 }
 
 const defaultDuration = time.Second
@@ -55,13 +68,73 @@ func WithWaitDuration(duration time.Duration) ReusableOption {
 	}
 }
 
+func WithSharing(key string, sharer containers.Sharer[Container]) ReusableOption {
+	return func(r *Reusable) {
+		r.sharing = &containers.Sharing[Container]{Key: key, Sharer: sharer}
+	}
+}
+
+func WithHealthCheck(interval time.Duration) ReusableOption {
+	return func(r *Reusable) {
+		r.daemonOpts = append(r.daemonOpts, containers.WithHealthCheck(interval))
+	}
+}
+
+func WithMaxUses(n int) ReusableOption {
+	return func(r *Reusable) {
+		r.daemonOpts = append(r.daemonOpts, containers.WithMaxUses(n))
+	}
+}
+
+func WithMaxLifetime(lifetime time.Duration) ReusableOption {
+	return func(r *Reusable) {
+		r.daemonOpts = append(r.daemonOpts, containers.WithMaxLifetime(lifetime))
+	}
+}
+
+func WithMaxActiveUsers(n int) ReusableOption {
+	return func(r *Reusable) {
+		r.daemonOpts = append(r.daemonOpts, containers.WithMaxActiveUsers(n))
+	}
+}
+
+func WithObserver(observer containers.Observer) ReusableOption {
+	return func(r *Reusable) {
+		r.daemonOpts = append(r.daemonOpts, containers.WithObserver(observer))
+	}
+}
+
+func WithLogger(logger *slog.Logger) ReusableOption {
+	return func(r *Reusable) {
+		r.daemonOpts = append(r.daemonOpts, containers.WithLogger(logger))
+	}
+}
+
+// WithPoolSize reuses up to size containers, every test is assigned to the least loaded one.
+func WithPoolSize(size int) ReusableOption {
+	return func(r *Reusable) {
+		r.poolSize = size
+	}
+}
+
 type Reusable struct {
 	ccf CreateContainerFunc
 
 	runDaemonOnce      sync.Once
-	dm                 *containers.ReusableDaemon
+	dm                 reusableDaemon
 	stopDaemon         context.CancelFunc
 	daemonWaitDuration time.Duration
+	daemonOpts         []containers.ReusableDaemonOption
+	poolSize           int
+	sharing            *containers.Sharing[Container]
+	templateDatabase   bool
+}
+
+type reusableDaemon interface {
+	Enter(ctx context.Context) (*containers.Lease[Container], error)
+	Warmup(ctx context.Context) error
+	Leases() []containers.LeaseInfo
+	Done() <-chan struct{}
 }
 
 func NewReusable(ccf CreateContainerFunc, opts ...ReusableOption) *Reusable {
@@ -78,19 +151,41 @@ func NewReusable(ccf CreateContainerFunc, opts ...ReusableOption) *Reusable {
 }
 
 func (r *Reusable) runDaemon() {
-	ccf := func(ctx context.Context) (any, error) {
-		return r.ccf(ctx)
-	}
-
 	ctx, cancel := context.WithCancel(context.Background())
 
-	daemon := containers.RunReusableDaemon(ctx, r.daemonWaitDuration, ccf)
+	ccf := containers.CreateContainerFunc[Container](r.ccf)
+
+	switch {
+	case r.poolSize > 1 && r.sharing != nil:
+		r.dm = containers.RunSharedReusablePool(ctx, r.poolSize, r.daemonWaitDuration, ccf, *r.sharing, r.daemonOpts...)
+	case r.poolSize > 1:
+		r.dm = containers.RunReusablePool(ctx, r.poolSize, r.daemonWaitDuration, ccf, r.daemonOpts...)
+	case r.sharing != nil:
+		r.dm = containers.RunSharedReusableDaemon(ctx, r.daemonWaitDuration, ccf, *r.sharing, r.daemonOpts...)
+	default:
+		r.dm = containers.RunReusableDaemon(ctx, r.daemonWaitDuration, ccf, r.daemonOpts...)
+	}
 
-	r.dm = daemon
 	r.stopDaemon = cancel
 }
 
+// Warmup creates the container in advance, it may be used with containers.Warmup.
+func (r *Reusable) Warmup(ctx context.Context) error {
+	r.runDaemonOnce.Do(r.runDaemon)
+
+	return r.dm.Warmup(ctx)
+}
+
+// Leases returns leases which are not released yet, it may be used with containers.ReportLeases.
+func (r *Reusable) Leases() []containers.LeaseInfo {
+	r.runDaemonOnce.Do(r.runDaemon)
+
+	return r.dm.Leases()
+}
+
 func (r *Reusable) Terminate(ctx context.Context) error {
+	r.runDaemonOnce.Do(r.runDaemon)
+
 	r.stopDaemon()
 
 	select {
@@ -105,35 +200,50 @@ func (r *Reusable) run(
 	ctx context.Context,
 	mig migrations.Migrations,
 	initialQueries ...migrations.Query,
-) (db *sql.DB, term func(), err error) {
+) (db *sql.DB, lease *containers.Lease[Container], term func(), err error) {
 	r.runDaemonOnce.Do(r.runDaemon)
 
-	pgCnt, err := r.enter(ctx)
+	lease, err = r.enter(ctx)
 	if err != nil {
-		return nil, func() {}, fmt.Errorf("enter to reuse container, %w", err)
+		return nil, nil, func() {}, fmt.Errorf("enter to reuse container, %w", err)
 	}
 
-	db, term, err = r.reuse(ctx, pgCnt, mig, initialQueries...)
+	db, term, err = r.reuse(ctx, lease, mig, initialQueries...)
 	if err != nil {
-		return db, term, fmt.Errorf("reuse container, %w", err)
+		return db, lease, term, fmt.Errorf("reuse container, %w", err)
 	}
 
-	return db, term, nil
+	return db, lease, term, nil
 }
 
 func (r *Reusable) reuse(
 	ctx context.Context,
-	pgCnt Container,
+	lease *containers.Lease[Container],
 	mig migrations.Migrations,
 	initialQueries ...migrations.Query,
 ) (db *sql.DB, term func(), err error) {
-	term = r.dm.Exit
+	ctx, span := tracing.Start(ctx, "postgrescontainer.Reusable.reuse")
+	defer tracing.End(span, &err)
+
+	if r.templateDatabase {
+		return r.reuseDatabase(ctx, lease, mig, initialQueries...)
+	}
+
+	term = lease.Release
+	pgCnt := lease.Container()
 
 	schemaName, err := r.createNewSchemaInContainer(ctx, pgCnt)
 	if err != nil {
 		return nil, term, err
 	}
 
+	span.SetAttributes(attribute.String("db.schema.name", schemaName))
+
+	term = func() {
+		dropSchema(ctx, pgCnt, schemaName)
+		lease.Release()
+	}
+
 	db, err = connectToSchema(ctx, pgCnt, schemaName)
 	if err != nil {
 		return db, term, err
@@ -141,7 +251,8 @@ func (r *Reusable) reuse(
 
 	term = func() {
 		_ = db.Close()
-		r.dm.Exit()
+		dropSchema(ctx, pgCnt, schemaName)
+		lease.Release()
 	}
 
 	if mig != nil {
@@ -178,7 +289,7 @@ func (r *Reusable) createNewSchemaInContainer(ctx context.Context, pgCnt Contain
 }
 
 func (r *Reusable) createSchema(ctx context.Context, db *sql.DB) (schemaName string, err error) {
-	schemaName = fmt.Sprintf("public%d", rand.Int64())
+	schemaName = newSchemaName(time.Now())
 
 	query := fmt.Sprintf("CREATE SCHEMA %s", schemaName)
 
@@ -199,11 +310,6 @@ func connectToSchema(ctx context.Context, pgCnt Container, schemaName string) (*
 	return db, nil
 }
 
-func (r *Reusable) enter(ctx context.Context) (Container, error) {
-	cnt, err := r.dm.Enter(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	return cnt.(Container), nil
+func (r *Reusable) enter(ctx context.Context) (*containers.Lease[Container], error) {
+	return r.dm.Enter(ctx)
 }
@@ -0,0 +1,261 @@
+package postgrescontainer
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"math/rand/v2"
+
+	"github.com/amidgo/containers"
+	"github.com/amidgo/containers/postgres/migrations"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WithTemplateDatabase gives every test its own database instead of a schema,
+// migrations run once per container into a template database keyed by migrations identity,
+// migrations must implement migrations.Identifier, every test database is a copy of the template.
+// Container must accept dbname connection argument, e.g. pgx driver does.
+func WithTemplateDatabase() ReusableOption {
+	return func(r *Reusable) {
+		r.templateDatabase = true
+	}
+}
+
+func (r *Reusable) reuseDatabase(
+	ctx context.Context,
+	lease *containers.Lease[Container],
+	mig migrations.Migrations,
+	initialQueries ...migrations.Query,
+) (db *sql.DB, term func(), err error) {
+	term = lease.Release
+	pgCnt := lease.Container()
+
+	dbName, err := createDatabaseFromTemplate(ctx, pgCnt, mig)
+	if err != nil {
+		return nil, term, err
+	}
+
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("db.namespace", dbName))
+
+	term = func() {
+		dropDatabase(ctx, pgCnt, dbName)
+		lease.Release()
+	}
+
+	db, err = pgCnt.Connect(ctx, "sslmode=disable", "dbname="+dbName)
+	if err != nil {
+		return nil, term, fmt.Errorf("connect to database %s, %w", dbName, err)
+	}
+
+	term = func() {
+		_ = db.Close()
+		dropDatabase(ctx, pgCnt, dbName)
+		lease.Release()
+	}
+
+	for _, initialQuery := range initialQueries {
+		err = migrations.ExecQuery(ctx, db, initialQuery)
+		if err != nil {
+			return db, term, err
+		}
+	}
+
+	return db, term, nil
+}
+
+func createDatabaseFromTemplate(ctx context.Context, pgCnt Container, mig migrations.Migrations) (dbName string, err error) {
+	baseDB, err := pgCnt.Connect(ctx, "sslmode=disable")
+	if err != nil {
+		return "", fmt.Errorf("connect to database, %w", err)
+	}
+
+	defer baseDB.Close()
+
+	templateName, err := ensureTemplateDatabase(ctx, pgCnt, baseDB, mig)
+	if err != nil {
+		return "", err
+	}
+
+	dbName = fmt.Sprintf("test%d", rand.Int64())
+
+	query := fmt.Sprintf("CREATE DATABASE %s TEMPLATE %s", dbName, templateName)
+
+	_, err = baseDB.ExecContext(ctx, query)
+	if err != nil {
+		return "", fmt.Errorf("create database %s from template %s, %w", dbName, templateName, err)
+	}
+
+	return dbName, nil
+}
+
+// ensureTemplateDatabase builds the template database if it does not exist yet.
+func ensureTemplateDatabase(
+	ctx context.Context,
+	pgCnt Container,
+	baseDB *sql.DB,
+	mig migrations.Migrations,
+) (templateName string, err error) {
+	if mig == nil {
+		mig = migrations.Nil
+	}
+
+	hash, err := migrationsHash(mig)
+	if err != nil {
+		return "", err
+	}
+
+	templateName = "template_" + hash
+
+	err = withAdvisoryLock(ctx, baseDB, templateName, func(conn *sql.Conn) error {
+		var exists bool
+
+		err := conn.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM pg_database WHERE datname = $1)", templateName).Scan(&exists)
+		if err != nil {
+			return fmt.Errorf("check template database %s exists, %w", templateName, err)
+		}
+
+		if exists {
+			return nil
+		}
+
+		return buildTemplateDatabase(ctx, pgCnt, conn, templateName, mig)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return templateName, nil
+}
+
+// withAdvisoryLock serializes f between all tests and processes using the database.
+func withAdvisoryLock(ctx context.Context, db *sql.DB, key string, f func(conn *sql.Conn) error) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("get connection, %w", err)
+	}
+
+	defer conn.Close()
+
+	_, err = conn.ExecContext(ctx, "SELECT pg_advisory_lock(hashtext($1))", key)
+	if err != nil {
+		return fmt.Errorf("acquire advisory lock %s, %w", key, err)
+	}
+
+	defer func() {
+		_, _ = conn.ExecContext(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock(hashtext($1))", key)
+	}()
+
+	return f(conn)
+}
+
+// buildTemplateDatabase runs migrations in a build database and renames it to templateName when they are done,
+// so a crash in the middle of the build never leaves a half migrated template.
+func buildTemplateDatabase(
+	ctx context.Context,
+	pgCnt Container,
+	conn *sql.Conn,
+	templateName string,
+	mig migrations.Migrations,
+) (err error) {
+	buildName := templateName + "_build"
+
+	// build database left by a crashed build is rebuilt from scratch
+	_, err = conn.ExecContext(ctx, fmt.Sprintf("DROP DATABASE IF EXISTS %s WITH (FORCE)", buildName))
+	if err != nil {
+		return fmt.Errorf("drop template build database %s, %w", buildName, err)
+	}
+
+	_, err = conn.ExecContext(ctx, "CREATE DATABASE "+buildName)
+	if err != nil {
+		return fmt.Errorf("create template build database %s, %w", buildName, err)
+	}
+
+	defer func() {
+		if err != nil {
+			_, _ = conn.ExecContext(context.WithoutCancel(ctx), "DROP DATABASE IF EXISTS "+buildName)
+		}
+	}()
+
+	buildDB, err := pgCnt.Connect(ctx, "sslmode=disable", "dbname="+buildName)
+	if err != nil {
+		return fmt.Errorf("connect to template build database %s, %w", buildName, err)
+	}
+
+	err = mig.Up(ctx, buildDB)
+	if err != nil {
+		_ = buildDB.Close()
+
+		return fmt.Errorf("up migrations in template build database %s, %w", buildName, err)
+	}
+
+	// database with open connections can not be renamed or copied
+	err = buildDB.Close()
+	if err != nil {
+		return fmt.Errorf("close template build database %s, %w", buildName, err)
+	}
+
+	query := fmt.Sprintf("ALTER DATABASE %s RENAME TO %s", buildName, templateName)
+
+	_, err = conn.ExecContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("rename template build database %s to %s, %w", buildName, templateName, err)
+	}
+
+	// renamed database is complete, the deferred drop of the build database doesn't touch it
+	query = fmt.Sprintf("ALTER DATABASE %s WITH IS_TEMPLATE true ALLOW_CONNECTIONS false", templateName)
+
+	_, err = conn.ExecContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("mark database %s as template, %w", templateName, err)
+	}
+
+	return nil
+}
+
+// migrationsHash is derived from the type and the identity of migrations,
+// migrations must implement migrations.Identifier, their value can't tell whether they build the same database.
+func migrationsHash(mig migrations.Migrations) (string, error) {
+	idr, ok := mig.(migrations.Identifier)
+	if !ok {
+		return "", fmt.Errorf("migrations %T don't implement migrations.Identifier", mig)
+	}
+
+	id, err := idr.Identity()
+	if err != nil {
+		return "", fmt.Errorf("get migrations identity, %w", err)
+	}
+
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%T:%s", mig, id)))
+
+	return hex.EncodeToString(hash[:8]), nil
+}
+
+func dropDatabase(ctx context.Context, pgCnt Container, dbName string) {
+	if containers.KeptOnFailure(ctx) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), dropTimeout)
+	defer cancel()
+
+	logger := containers.LoggerFromContext(ctx)
+
+	baseDB, err := pgCnt.Connect(ctx, "sslmode=disable")
+	if err != nil {
+		logger.Error("failed to connect to postgres to drop test database", "database", dbName, "error", err)
+
+		return
+	}
+
+	defer baseDB.Close()
+
+	query := fmt.Sprintf("DROP DATABASE IF EXISTS %s WITH (FORCE)", dbName)
+
+	_, err = baseDB.ExecContext(ctx, query)
+	if err != nil {
+		logger.Error("failed to drop test database", "database", dbName, "error", err)
+	}
+}
@@ -69,6 +69,12 @@ func Test_ReuseForTesting(t *testing.T) {
 
 	t.Run("GlobalReuseable", testReuse(postgrescontainerrunner.Reusable()))
 	t.Run("NewReuseable_RunContainer", testReuse(testReusable))
+	t.Run("NewReuseable_TemplateDatabase", testReuse(
+		postgrescontainer.NewReusable(
+			postgrescontainerrunner.RunContainer(nil),
+			postgrescontainer.WithTemplateDatabase(),
+		),
+	))
 }
 
 func Test_GooseMigrations(t *testing.T) {
@@ -1,9 +1,23 @@
 package postgresrunner
 
-import pgcnt "github.com/amidgo/containers/postgres"
+import (
+	"github.com/amidgo/containers"
+	pgcnt "github.com/amidgo/containers/postgres"
+)
 
-var reusable = pgcnt.NewReusable(RunContainer(nil))
+var reusable = pgcnt.NewReusable(RunContainer(nil), reusableOptions()...)
 
 func Reusable() *pgcnt.Reusable {
 	return reusable
 }
+
+func reusableOptions() []pgcnt.ReusableOption {
+	// keep-alive containers are already reattached by every process
+	if !containers.ReuseAcrossProcesses() || containers.KeepAlive() {
+		return nil
+	}
+
+	return []pgcnt.ReusableOption{
+		pgcnt.WithSharing("postgres:"+containerPostgresImage(nil), Sharer()),
+	}
+}
@@ -4,16 +4,21 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/amidgo/containers"
+	"github.com/amidgo/containers/internal/docker"
+	"github.com/amidgo/containers/internal/tracing"
 	postgrescontainer "github.com/amidgo/containers/postgres"
 	"github.com/amidgo/containers/postgres/migrations"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/modules/postgres"
 	"github.com/testcontainers/testcontainers-go/wait"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 func RunForTestingConfig(
@@ -22,14 +27,27 @@ func RunForTestingConfig(
 	migrations migrations.Migrations,
 	initialQueries ...migrations.Query,
 ) *sql.DB {
-	containers.SkipDisabled(t)
+	containers.SkipDisabled(t, containers.BackendPostgres)
+	containers.RequireDocker(t)
 
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(containers.ContextWithTest(context.Background(), t))
 	t.Cleanup(cancel)
 
-	db, term, err := RunConfig(ctx, cfg, migrations, initialQueries...)
+	since := time.Now()
+
+	pgCnt, err := runContainer(cfg, false)(ctx)
+	if err != nil {
+		t.Fatal(err)
+
+		return nil
+	}
+
+	db, term, err := postgrescontainer.Init(ctx, pgCnt, migrations, initialQueries...)
 	t.Cleanup(term)
 
+	containers.DumpLogsOnFailure(t, since, pgCnt)
+	postgrescontainer.KeepOnFailure(t, pgCnt, db)
+
 	if err != nil {
 		t.Fatal(err)
 
@@ -66,7 +84,7 @@ func RunConfig(
 	migrations migrations.Migrations,
 	initialQueries ...migrations.Query,
 ) (db *sql.DB, term func(), err error) {
-	pgCnt, err := RunContainer(cfg)(ctx)
+	pgCnt, err := runContainer(cfg, false)(ctx)
 	if err != nil {
 		return nil, func() {}, err
 	}
@@ -81,6 +99,8 @@ type ContainerConfig struct {
 	PostgresImage             string
 	DriverName                string
 	DisableTestContainersLogs bool
+	// Logger is used instead of the logger of the test or the global one
+	Logger *slog.Logger
 }
 
 func containerDBName(cfg *ContainerConfig) string {
@@ -138,6 +158,14 @@ func containerDriverName(cfg *ContainerConfig) string {
 	return defaultDriverName
 }
 
+func containerLogger(cfg *ContainerConfig) *slog.Logger {
+	if cfg == nil {
+		return nil
+	}
+
+	return cfg.Logger
+}
+
 func containerDisableTestContainersLogs(cfg *ContainerConfig) bool {
 	if cfg == nil {
 		return false
@@ -146,13 +174,27 @@ func containerDisableTestContainersLogs(cfg *ContainerConfig) bool {
 	return cfg.DisableTestContainersLogs
 }
 
+// RunContainer keeps the container running after tests when containers.KeepAlive is enabled,
+// next runs reattach to it, keep-alive containers are removed by containers stop command.
 func RunContainer(cfg *ContainerConfig) postgrescontainer.CreateContainerFunc {
-	return func(ctx context.Context) (postgrescontainer.Container, error) {
+	return runContainer(cfg, containers.KeepAlive())
+}
+
+func runContainer(cfg *ContainerConfig, keepAlive bool) postgrescontainer.CreateContainerFunc {
+	return func(ctx context.Context) (_ postgrescontainer.Container, err error) {
+		err = containers.DockerAvailable(ctx)
+		if err != nil {
+			return nil, err
+		}
+
 		postgresImage := containerPostgresImage(cfg)
 		dbName := containerDBName(cfg)
 		dbUser := containerDBUser(cfg)
 		dbPassword := containerDBPassword(cfg)
 
+		ctx, span := tracing.Start(ctx, "postgresrunner.RunContainer", attribute.String("container.image", postgresImage))
+		defer tracing.End(span, &err)
+
 		opts := []testcontainers.ContainerCustomizer{
 			postgres.WithDatabase(dbName),
 			postgres.WithUsername(dbUser),
@@ -161,12 +203,19 @@ func RunContainer(cfg *ContainerConfig) postgrescontainer.CreateContainerFunc {
 				wait.ForLog("database system is ready to accept connections").
 					WithOccurrence(2),
 			),
+			docker.WithSessionLabels(),
 		}
 
 		if containerDisableTestContainersLogs(cfg) {
 			opts = append(opts, testcontainers.WithLogger(noopLogger{}))
 		}
 
+		if keepAlive {
+			name := docker.KeepAliveName("postgres", postgresImage, dbName, dbUser, dbPassword)
+
+			opts = append(opts, docker.WithKeepAlive(name))
+		}
+
 		postgresContainer, err := postgres.Run(ctx,
 			postgresImage,
 			opts...,
@@ -178,8 +227,16 @@ func RunContainer(cfg *ContainerConfig) postgrescontainer.CreateContainerFunc {
 		driverName := containerDriverName(cfg)
 
 		cnt := container{
+			logger:     containerLogger(cfg),
 			driverName: driverName,
 			cnt:        postgresContainer,
+			keepAlive:  keepAlive,
+			kept:       &atomic.Bool{},
+			unregister: func() {},
+		}
+
+		if !keepAlive {
+			cnt.unregister = containers.RegisterCleanup(postgresContainer)
 		}
 
 		return cnt, nil
@@ -192,8 +249,12 @@ type noopLogger struct{}
 func (noopLogger) Printf(string, ...interface{}) {}
 
 type container struct {
+	logger     *slog.Logger
 	driverName string
 	cnt        *postgres.PostgresContainer
+	keepAlive  bool
+	kept       *atomic.Bool
+	unregister func()
 }
 
 func (c container) Connect(ctx context.Context, args ...string) (*sql.DB, error) {
@@ -202,10 +263,13 @@ func (c container) Connect(ctx context.Context, args ...string) (*sql.DB, error)
 		return nil, fmt.Errorf("get connection string, %w", err)
 	}
 
-	if testing.Testing() {
-		log.Printf("dataSourceName: %s", dataSourceName)
+	logger := c.logger
+	if logger == nil {
+		logger = containers.LoggerFromContext(ctx)
 	}
 
+	containers.RedactLogger(logger).Debug("connect to postgres", "data_source_name", dataSourceName)
+
 	db, err := sql.Open(c.driverName, dataSourceName)
 	if err != nil {
 		return nil, fmt.Errorf("open connection, %w", err)
@@ -214,6 +278,50 @@ func (c container) Connect(ctx context.Context, args ...string) (*sql.DB, error)
 	return db, nil
 }
 
+func (c container) ConnectionString(ctx context.Context, args ...string) (string, error) {
+	return c.cnt.ConnectionString(ctx, args...)
+}
+
+func (c container) Keep() {
+	c.kept.Store(true)
+	c.unregister()
+}
+
 func (c container) Terminate(ctx context.Context) error {
+	if c.keepAlive || c.kept.Load() {
+		return nil
+	}
+
+	c.unregister()
+
 	return c.cnt.Terminate(ctx)
 }
+
+func (c container) ContainerID() string {
+	return c.cnt.GetContainerID()
+}
+
+func (c container) Ping(ctx context.Context) error {
+	dataSourceName, err := c.cnt.ConnectionString(ctx, "sslmode=disable")
+	if err != nil {
+		return fmt.Errorf("get connection string, %w", err)
+	}
+
+	return ping(ctx, c.driverName, dataSourceName)
+}
+
+func ping(ctx context.Context, driverName, dataSourceName string) error {
+	db, err := sql.Open(driverName, dataSourceName)
+	if err != nil {
+		return fmt.Errorf("open connection, %w", err)
+	}
+
+	defer db.Close()
+
+	err = db.PingContext(ctx)
+	if err != nil {
+		return fmt.Errorf("ping database, %w", err)
+	}
+
+	return nil
+}
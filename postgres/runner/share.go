@@ -0,0 +1,87 @@
+package postgresrunner
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/amidgo/containers"
+	"github.com/amidgo/containers/internal/docker"
+	postgrescontainer "github.com/amidgo/containers/postgres"
+)
+
+func Sharer() containers.Sharer[postgrescontainer.Container] {
+	return sharer{}
+}
+
+type sharedContainerState struct {
+	ContainerID      string `json:"container_id"`
+	ConnectionString string `json:"connection_string"`
+	DriverName       string `json:"driver_name"`
+}
+
+type sharer struct{}
+
+func (sharer) Share(ctx context.Context, cnt postgrescontainer.Container) ([]byte, error) {
+	pgCnt, ok := cnt.(container)
+	if !ok {
+		return nil, fmt.Errorf("unsupported container type %T", cnt)
+	}
+
+	connectionString, err := pgCnt.cnt.ConnectionString(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get connection string, %w", err)
+	}
+
+	state := sharedContainerState{
+		ContainerID:      pgCnt.cnt.GetContainerID(),
+		ConnectionString: strings.TrimSuffix(connectionString, "?"),
+		DriverName:       pgCnt.driverName,
+	}
+
+	return json.Marshal(state)
+}
+
+func (sharer) Attach(_ context.Context, data []byte) (postgrescontainer.Container, error) {
+	var state sharedContainerState
+
+	err := json.Unmarshal(data, &state)
+	if err != nil {
+		return nil, fmt.Errorf("decode shared container state, %w", err)
+	}
+
+	return sharedContainer{state: state}, nil
+}
+
+type sharedContainer struct {
+	state sharedContainerState
+}
+
+func (s sharedContainer) ConnectionString(_ context.Context, args ...string) (string, error) {
+	return s.state.ConnectionString + "?" + strings.Join(args, "&"), nil
+}
+
+func (s sharedContainer) Connect(ctx context.Context, args ...string) (*sql.DB, error) {
+	dataSourceName, _ := s.ConnectionString(ctx, args...)
+
+	db, err := sql.Open(s.state.DriverName, dataSourceName)
+	if err != nil {
+		return nil, fmt.Errorf("open connection, %w", err)
+	}
+
+	return db, nil
+}
+
+func (s sharedContainer) Terminate(ctx context.Context) error {
+	return docker.RemoveContainer(ctx, s.state.ContainerID)
+}
+
+func (s sharedContainer) ContainerID() string {
+	return s.state.ContainerID
+}
+
+func (s sharedContainer) Ping(ctx context.Context) error {
+	return ping(ctx, s.state.DriverName, s.state.ConnectionString+"?sslmode=disable")
+}
@@ -0,0 +1,120 @@
+package postgrescontainer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amidgo/containers"
+)
+
+// schemaPrefix marks schemas created by Reusable, the name also holds the creation time,
+// so schemas left by crashed runs are recognized by DropOrphanedSchemas.
+const schemaPrefix = "containers_"
+
+// dropTimeout bounds removal of per test state, which runs even if ctx of the test is already done.
+const dropTimeout = 10 * time.Second
+
+func newSchemaName(now time.Time) string {
+	return fmt.Sprintf("%s%d_%d", schemaPrefix, now.Unix(), rand.Uint32())
+}
+
+func schemaCreatedAt(schemaName string) (createdAt time.Time, ok bool) {
+	rest, ok := strings.CutPrefix(schemaName, schemaPrefix)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	unix, _, ok := strings.Cut(rest, "_")
+	if !ok {
+		return time.Time{}, false
+	}
+
+	sec, err := strconv.ParseInt(unix, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return time.Unix(sec, 0), true
+}
+
+func dropSchema(ctx context.Context, pgCnt Container, schemaName string) {
+	if containers.KeptOnFailure(ctx) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), dropTimeout)
+	defer cancel()
+
+	logger := containers.LoggerFromContext(ctx)
+
+	baseDB, err := pgCnt.Connect(ctx, "sslmode=disable")
+	if err != nil {
+		logger.Error("failed to connect to postgres to drop test schema", "schema", schemaName, "error", err)
+
+		return
+	}
+
+	defer baseDB.Close()
+
+	_, err = baseDB.ExecContext(ctx, fmt.Sprintf("DROP SCHEMA IF EXISTS %s CASCADE", schemaName))
+	if err != nil {
+		logger.Error("failed to drop test schema", "schema", schemaName, "error", err)
+	}
+}
+
+// DropOrphanedSchemas drops schemas created by Reusable more than olderThan ago,
+// e.g. schemas left in a long-lived external database by crashed runs or kept after failures.
+func DropOrphanedSchemas(ctx context.Context, db *sql.DB, olderThan time.Duration) (dropped []string, err error) {
+	rows, err := db.QueryContext(ctx, "SELECT nspname FROM pg_namespace WHERE starts_with(nspname, $1)", schemaPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("list schemas, %w", err)
+	}
+
+	var schemaNames []string
+
+	for rows.Next() {
+		var schemaName string
+
+		err = rows.Scan(&schemaName)
+		if err != nil {
+			_ = rows.Close()
+
+			return nil, fmt.Errorf("scan schema name, %w", err)
+		}
+
+		schemaNames = append(schemaNames, schemaName)
+	}
+
+	err = rows.Close()
+	if err != nil {
+		return nil, fmt.Errorf("close schemas rows, %w", err)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("list schemas, %w", err)
+	}
+
+	deadline := time.Now().Add(-olderThan)
+
+	for _, schemaName := range schemaNames {
+		createdAt, ok := schemaCreatedAt(schemaName)
+		if !ok || createdAt.After(deadline) {
+			continue
+		}
+
+		_, err = db.ExecContext(ctx, fmt.Sprintf("DROP SCHEMA IF EXISTS %s CASCADE", schemaName))
+		if err != nil {
+			return dropped, fmt.Errorf("drop schema %s, %w", schemaName, err)
+		}
+
+		dropped = append(dropped, schemaName)
+	}
+
+	return dropped, nil
+}
@@ -0,0 +1,58 @@
+package postgrescontainer_test
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"testing"
+	"time"
+
+	postgrescontainer "github.com/amidgo/containers/postgres"
+	"github.com/amidgo/containers/postgres/migrations"
+	postgrescontainerrunner "github.com/amidgo/containers/postgres/runner"
+)
+
+func Test_DropOrphanedSchemas(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	db := postgrescontainer.ReuseForTesting(t,
+		postgrescontainerrunner.Reusable(),
+		migrations.Nil,
+	)
+
+	orphanedSchema := fmt.Sprintf("containers_%d_%d", time.Now().Add(-2*time.Hour).Unix(), rand.Uint32())
+	foreignSchema := fmt.Sprintf("orphaned_foreign_schema_%d", rand.Uint32())
+
+	for _, schemaName := range []string{orphanedSchema, foreignSchema} {
+		_, err := db.ExecContext(ctx, "CREATE SCHEMA "+schemaName)
+		if err != nil {
+			t.Fatalf("create schema %s, %s", schemaName, err)
+		}
+	}
+
+	t.Cleanup(func() {
+		_, _ = db.ExecContext(context.Background(), "DROP SCHEMA IF EXISTS "+foreignSchema)
+	})
+
+	var currentSchema string
+
+	err := db.QueryRowContext(ctx, "SELECT current_schema()").Scan(&currentSchema)
+	if err != nil {
+		t.Fatalf("get current schema, %s", err)
+	}
+
+	dropped, err := postgrescontainer.DropOrphanedSchemas(ctx, db, time.Hour)
+	if err != nil {
+		t.Fatalf("drop orphaned schemas, expected no error, actual %s", err)
+	}
+
+	if !slices.Contains(dropped, orphanedSchema) {
+		t.Fatalf("expected %s dropped, actual dropped %v", orphanedSchema, dropped)
+	}
+
+	if slices.Contains(dropped, currentSchema) || slices.Contains(dropped, foreignSchema) {
+		t.Fatalf("expected only orphaned schemas dropped, actual dropped %v", dropped)
+	}
+}
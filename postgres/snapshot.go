@@ -0,0 +1,279 @@
+package postgrescontainer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/amidgo/containers/internal/tracing"
+)
+
+// DatabaseSnapshot holds data of all tables of the current schema of db copied into a snapshot schema.
+type DatabaseSnapshot struct {
+	db             *sql.DB
+	schemaName     string
+	snapshotSchema string
+	tables         []snapshotTable
+	sequences      []snapshotSequence
+}
+
+type snapshotTable struct {
+	name    string
+	columns []string
+}
+
+type snapshotSequence struct {
+	name     string
+	value    int64
+	isCalled bool
+}
+
+// Snapshot copies data of all tables and values of all sequences of the current schema of db,
+// db is restored to the snapshot with Restore any number of times.
+// Snapshot schema follows naming of Reusable schemas, so forgotten snapshots are removed by DropOrphanedSchemas.
+func Snapshot(ctx context.Context, db *sql.DB) (snapshot *DatabaseSnapshot, err error) {
+	ctx, span := tracing.Start(ctx, "postgrescontainer.Snapshot")
+	defer tracing.End(span, &err)
+
+	snapshot = &DatabaseSnapshot{
+		db:             db,
+		snapshotSchema: newSchemaName(time.Now()),
+	}
+
+	err = db.QueryRowContext(ctx, "SELECT current_schema()").Scan(&snapshot.schemaName)
+	if err != nil {
+		return nil, fmt.Errorf("get current schema, %w", err)
+	}
+
+	snapshot.tables, err = listSnapshotTables(ctx, db, snapshot.schemaName)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot.sequences, err = listSnapshotSequences(ctx, db, snapshot.schemaName)
+	if err != nil {
+		return nil, err
+	}
+
+	err = snapshot.copyTables(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+func (s *DatabaseSnapshot) copyTables(ctx context.Context) (err error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction, %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	_, err = tx.ExecContext(ctx, "CREATE SCHEMA "+quoteIdent(s.snapshotSchema))
+	if err != nil {
+		return fmt.Errorf("create snapshot schema %s, %w", s.snapshotSchema, err)
+	}
+
+	for _, table := range s.tables {
+		query := fmt.Sprintf("CREATE TABLE %s AS SELECT %s FROM %s",
+			qualifiedName(s.snapshotSchema, table.name),
+			joinIdents(table.columns),
+			qualifiedName(s.schemaName, table.name),
+		)
+
+		_, err = tx.ExecContext(ctx, query)
+		if err != nil {
+			return fmt.Errorf("copy table %s, %w", table.name, err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("commit snapshot, %w", err)
+	}
+
+	return nil
+}
+
+// Restore replaces data of snapshot tables with the data copied by Snapshot and resets sequences,
+// foreign keys are not checked while data is restored, so the database user must be a superuser.
+func Restore(ctx context.Context, snapshot *DatabaseSnapshot) (err error) {
+	ctx, span := tracing.Start(ctx, "postgrescontainer.Restore")
+	defer tracing.End(span, &err)
+
+	tx, err := snapshot.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction, %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	_, err = tx.ExecContext(ctx, "SET LOCAL session_replication_role = replica")
+	if err != nil {
+		return fmt.Errorf("disable foreign keys, %w", err)
+	}
+
+	if len(snapshot.tables) > 0 {
+		tableNames := make([]string, 0, len(snapshot.tables))
+
+		for _, table := range snapshot.tables {
+			tableNames = append(tableNames, qualifiedName(snapshot.schemaName, table.name))
+		}
+
+		_, err = tx.ExecContext(ctx, "TRUNCATE "+strings.Join(tableNames, ", "))
+		if err != nil {
+			return fmt.Errorf("truncate tables, %w", err)
+		}
+	}
+
+	for _, table := range snapshot.tables {
+		columns := joinIdents(table.columns)
+
+		query := fmt.Sprintf("INSERT INTO %s (%s) OVERRIDING SYSTEM VALUE SELECT %s FROM %s",
+			qualifiedName(snapshot.schemaName, table.name),
+			columns,
+			columns,
+			qualifiedName(snapshot.snapshotSchema, table.name),
+		)
+
+		_, err = tx.ExecContext(ctx, query)
+		if err != nil {
+			return fmt.Errorf("restore table %s, %w", table.name, err)
+		}
+	}
+
+	for _, seq := range snapshot.sequences {
+		_, err = tx.ExecContext(ctx, "SELECT setval($1, $2, $3)",
+			qualifiedName(snapshot.schemaName, seq.name),
+			seq.value,
+			seq.isCalled,
+		)
+		if err != nil {
+			return fmt.Errorf("restore sequence %s, %w", seq.name, err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("commit restore, %w", err)
+	}
+
+	return nil
+}
+
+// Drop removes the snapshot schema, snapshot must not be restored after Drop.
+func (s *DatabaseSnapshot) Drop(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, fmt.Sprintf("DROP SCHEMA IF EXISTS %s CASCADE", quoteIdent(s.snapshotSchema)))
+	if err != nil {
+		return fmt.Errorf("drop snapshot schema %s, %w", s.snapshotSchema, err)
+	}
+
+	return nil
+}
+
+// listSnapshotTables returns tables of the schema with their columns, generated columns are skipped,
+// partitions are copied and restored with their parent tables.
+func listSnapshotTables(ctx context.Context, db *sql.DB, schemaName string) ([]snapshotTable, error) {
+	const query = `SELECT c.relname, a.attname
+FROM pg_class c
+JOIN pg_namespace n ON n.oid = c.relnamespace
+JOIN pg_attribute a ON a.attrelid = c.oid
+WHERE c.relkind IN ('r', 'p') AND NOT c.relispartition AND n.nspname = $1
+	AND a.attnum > 0 AND NOT a.attisdropped AND a.attgenerated = ''
+ORDER BY c.relname, a.attnum`
+
+	rows, err := db.QueryContext(ctx, query, schemaName)
+	if err != nil {
+		return nil, fmt.Errorf("list tables, %w", err)
+	}
+
+	defer rows.Close()
+
+	var tables []snapshotTable
+
+	for rows.Next() {
+		var tableName, columnName string
+
+		err = rows.Scan(&tableName, &columnName)
+		if err != nil {
+			return nil, fmt.Errorf("scan table column, %w", err)
+		}
+
+		if len(tables) == 0 || tables[len(tables)-1].name != tableName {
+			tables = append(tables, snapshotTable{name: tableName})
+		}
+
+		last := &tables[len(tables)-1]
+		last.columns = append(last.columns, columnName)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("list tables, %w", err)
+	}
+
+	return tables, nil
+}
+
+func listSnapshotSequences(ctx context.Context, db *sql.DB, schemaName string) ([]snapshotSequence, error) {
+	const query = `SELECT sequencename, COALESCE(last_value, start_value), last_value IS NOT NULL
+FROM pg_sequences
+WHERE schemaname = $1`
+
+	rows, err := db.QueryContext(ctx, query, schemaName)
+	if err != nil {
+		return nil, fmt.Errorf("list sequences, %w", err)
+	}
+
+	defer rows.Close()
+
+	var sequences []snapshotSequence
+
+	for rows.Next() {
+		var seq snapshotSequence
+
+		err = rows.Scan(&seq.name, &seq.value, &seq.isCalled)
+		if err != nil {
+			return nil, fmt.Errorf("scan sequence, %w", err)
+		}
+
+		sequences = append(sequences, seq)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("list sequences, %w", err)
+	}
+
+	return sequences, nil
+}
+
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func qualifiedName(schemaName, name string) string {
+	return quoteIdent(schemaName) + "." + quoteIdent(name)
+}
+
+func joinIdents(names []string) string {
+	quoted := make([]string, 0, len(names))
+
+	for _, name := range names {
+		quoted = append(quoted, quoteIdent(name))
+	}
+
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,97 @@
+package postgrescontainer_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	postgrescontainer "github.com/amidgo/containers/postgres"
+	goosemigrations "github.com/amidgo/containers/postgres/migrations/goose"
+	postgrescontainerrunner "github.com/amidgo/containers/postgres/runner"
+)
+
+func Test_SnapshotRestore(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	db := postgrescontainer.ReuseForTesting(t,
+		postgrescontainerrunner.Reusable(),
+		goosemigrations.New(os.DirFS("./testdata/migrations")),
+		"INSERT INTO users (name) VALUES ('Dima')",
+	)
+
+	snapshot, err := postgrescontainer.Snapshot(ctx, db)
+	if err != nil {
+		t.Fatalf("snapshot, expected no error, actual %s", err)
+	}
+
+	t.Cleanup(func() {
+		_ = snapshot.Drop(context.Background())
+	})
+
+	for _, name := range []string{"amidman", "amidgo"} {
+		t.Run(name, func(t *testing.T) {
+			err := postgrescontainer.Restore(ctx, snapshot)
+			if err != nil {
+				t.Fatalf("restore, expected no error, actual %s", err)
+			}
+
+			var id int
+
+			err = db.QueryRowContext(ctx, "INSERT INTO users (name) VALUES ($1) RETURNING id", name).Scan(&id)
+			if err != nil {
+				t.Fatalf("insert user %s, %s", name, err)
+			}
+
+			if id != 2 {
+				t.Fatalf("expected user id sequence restored, expected id 2, actual %d", id)
+			}
+
+			assertUsersCount(t, ctx, db, 2)
+		})
+	}
+}
+
+func Test_SnapshotRestore_PartitionedTable(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	db := postgrescontainer.ReuseForTesting(t,
+		postgrescontainerrunner.Reusable(),
+		goosemigrations.New(os.DirFS("./testdata/migrations")),
+		"CREATE TABLE events (id INT NOT NULL, created_at DATE NOT NULL, PRIMARY KEY (id, created_at)) PARTITION BY RANGE (created_at)",
+		"CREATE TABLE events_2025 PARTITION OF events FOR VALUES FROM ('2025-01-01') TO ('2026-01-01')",
+		"INSERT INTO events (id, created_at) VALUES (1, '2025-06-01')",
+	)
+
+	snapshot, err := postgrescontainer.Snapshot(ctx, db)
+	if err != nil {
+		t.Fatalf("snapshot, expected no error, actual %s", err)
+	}
+
+	t.Cleanup(func() {
+		_ = snapshot.Drop(context.Background())
+	})
+
+	_, err = db.ExecContext(ctx, "INSERT INTO events (id, created_at) VALUES (2, '2025-07-01')")
+	if err != nil {
+		t.Fatalf("insert event, %s", err)
+	}
+
+	// partition rows are restored only through the parent table, otherwise they are duplicated
+	err = postgrescontainer.Restore(ctx, snapshot)
+	if err != nil {
+		t.Fatalf("restore, expected no error, actual %s", err)
+	}
+
+	var count int
+
+	err = db.QueryRowContext(ctx, "SELECT count(*) FROM events").Scan(&count)
+	if err != nil {
+		t.Fatalf("count events, %s", err)
+	}
+
+	if count != 1 {
+		t.Fatalf("expected 1 event after restore, actual %d", count)
+	}
+}
@@ -0,0 +1,247 @@
+package postgrescontainer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/amidgo/containers"
+	"github.com/amidgo/containers/internal/tracing"
+	"github.com/amidgo/containers/postgres/migrations"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// txSchemaPrefix marks schemas migrated once per container and shared by all transactions of the same migrations.
+const txSchemaPrefix = schemaPrefix + "tx_"
+
+// TxForTesting gives the test a transaction in a schema migrated once per container,
+// everything done in the transaction, including initial queries, is rolled back when the test ends.
+// Migrations must implement migrations.Identifier.
+//
+// TxForTesting is incompatible with t.Parallel: transactions of all tests work on the same tables,
+// so parallel tests block each other on row locks and unique indexes or deadlock, and they see sequences advanced by others.
+// Parallel tests should use ReuseForTesting, it gives every test its own schema.
+// The transaction must not be used by parallel subtests either, see SavepointForTesting.
+func TxForTesting(
+	t *testing.T,
+	reuse *Reusable,
+	mig migrations.Migrations,
+	initialQueries ...migrations.Query,
+) *sql.Tx {
+	containers.SkipDisabled(t, containers.BackendPostgres)
+
+	ctx, cancel := context.WithCancel(containers.ContextWithTest(context.Background(), t))
+	t.Cleanup(cancel)
+
+	since := time.Now()
+
+	tx, pgCnt, term, err := reuse.runTx(ctx, mig, initialQueries...)
+	t.Cleanup(term)
+
+	containers.DumpLogsOnFailure(t, since, pgCnt)
+
+	if err != nil {
+		containers.HandleNoDocker(t, err)
+
+		t.Fatalf("begin transaction in reuse container, err: %s", err)
+
+		return nil
+	}
+
+	return tx
+}
+
+// Tx is TxForTesting for use outside of tests, term rolls back the transaction,
+// the transaction is rolled back by database/sql as well when ctx is done.
+func Tx(
+	ctx context.Context,
+	reuse *Reusable,
+	mig migrations.Migrations,
+	initialQueries ...migrations.Query,
+) (tx *sql.Tx, term func(), err error) {
+	tx, _, term, err = reuse.runTx(ctx, mig, initialQueries...)
+
+	return tx, term, err
+}
+
+// SavepointForTesting sets a savepoint in tx, tx is rolled back to it when the subtest ends,
+// so subtests sharing tx of the parent test start from the same state.
+func SavepointForTesting(t *testing.T, tx *sql.Tx) {
+	savepointName := fmt.Sprintf("containers_savepoint_%d", rand.Uint32())
+
+	_, err := tx.ExecContext(context.Background(), "SAVEPOINT "+savepointName)
+	if err != nil {
+		t.Fatalf("set savepoint %s, err: %s", savepointName, err)
+
+		return
+	}
+
+	t.Cleanup(func() {
+		_, err := tx.ExecContext(context.Background(), "ROLLBACK TO SAVEPOINT "+savepointName)
+		if err != nil {
+			t.Errorf("rollback to savepoint %s, err: %s", savepointName, err)
+		}
+	})
+}
+
+func (r *Reusable) runTx(
+	ctx context.Context,
+	mig migrations.Migrations,
+	initialQueries ...migrations.Query,
+) (tx *sql.Tx, pgCnt Container, term func(), err error) {
+	r.runDaemonOnce.Do(r.runDaemon)
+
+	lease, err := r.enter(ctx)
+	if err != nil {
+		return nil, nil, func() {}, fmt.Errorf("enter to reuse container, %w", err)
+	}
+
+	tx, term, err = r.reuseTx(ctx, lease, mig, initialQueries...)
+	if err != nil {
+		return tx, lease.Container(), term, fmt.Errorf("reuse container, %w", err)
+	}
+
+	return tx, lease.Container(), term, nil
+}
+
+func (r *Reusable) reuseTx(
+	ctx context.Context,
+	lease *containers.Lease[Container],
+	mig migrations.Migrations,
+	initialQueries ...migrations.Query,
+) (tx *sql.Tx, term func(), err error) {
+	ctx, span := tracing.Start(ctx, "postgrescontainer.Reusable.reuseTx")
+	defer tracing.End(span, &err)
+
+	term = lease.Release
+	pgCnt := lease.Container()
+
+	schemaName, err := ensureTxSchema(ctx, pgCnt, mig)
+	if err != nil {
+		return nil, term, err
+	}
+
+	span.SetAttributes(attribute.String("db.schema.name", schemaName))
+
+	db, err := connectToSchema(ctx, pgCnt, schemaName)
+	if err != nil {
+		return nil, term, err
+	}
+
+	term = func() {
+		_ = db.Close()
+		lease.Release()
+	}
+
+	tx, err = db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, term, fmt.Errorf("begin transaction, %w", err)
+	}
+
+	term = func() {
+		_ = tx.Rollback()
+		_ = db.Close()
+		lease.Release()
+	}
+
+	for _, initialQuery := range initialQueries {
+		err = migrations.ExecQuery(ctx, tx, initialQuery)
+		if err != nil {
+			return tx, term, err
+		}
+	}
+
+	return tx, term, nil
+}
+
+// ensureTxSchema migrates the schema of mig if it does not exist yet.
+func ensureTxSchema(ctx context.Context, pgCnt Container, mig migrations.Migrations) (schemaName string, err error) {
+	if mig == nil {
+		mig = migrations.Nil
+	}
+
+	hash, err := migrationsHash(mig)
+	if err != nil {
+		return "", err
+	}
+
+	schemaName = txSchemaPrefix + hash
+
+	baseDB, err := pgCnt.Connect(ctx, "sslmode=disable")
+	if err != nil {
+		return "", fmt.Errorf("connect to database, %w", err)
+	}
+
+	defer baseDB.Close()
+
+	err = withAdvisoryLock(ctx, baseDB, schemaName, func(conn *sql.Conn) error {
+		var exists bool
+
+		err := conn.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM pg_namespace WHERE nspname = $1)", schemaName).Scan(&exists)
+		if err != nil {
+			return fmt.Errorf("check schema %s exists, %w", schemaName, err)
+		}
+
+		if exists {
+			return nil
+		}
+
+		return buildTxSchema(ctx, pgCnt, conn, schemaName, mig)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return schemaName, nil
+}
+
+// buildTxSchema runs migrations in a build schema and renames it to schemaName when they are done,
+// so a crash in the middle of the build never leaves a half migrated schema.
+func buildTxSchema(
+	ctx context.Context,
+	pgCnt Container,
+	conn *sql.Conn,
+	schemaName string,
+	mig migrations.Migrations,
+) (err error) {
+	buildName := schemaName + "_build"
+
+	// build schema left by a crashed build is rebuilt from scratch
+	_, err = conn.ExecContext(ctx, fmt.Sprintf("DROP SCHEMA IF EXISTS %s CASCADE", buildName))
+	if err != nil {
+		return fmt.Errorf("drop build schema %s, %w", buildName, err)
+	}
+
+	_, err = conn.ExecContext(ctx, "CREATE SCHEMA "+buildName)
+	if err != nil {
+		return fmt.Errorf("create build schema %s, %w", buildName, err)
+	}
+
+	defer func() {
+		if err != nil {
+			_, _ = conn.ExecContext(context.WithoutCancel(ctx), fmt.Sprintf("DROP SCHEMA IF EXISTS %s CASCADE", buildName))
+		}
+	}()
+
+	db, err := connectToSchema(ctx, pgCnt, buildName)
+	if err != nil {
+		return err
+	}
+
+	defer db.Close()
+
+	err = mig.Up(ctx, db)
+	if err != nil {
+		return fmt.Errorf("up migrations in build schema %s, %w", buildName, err)
+	}
+
+	_, err = conn.ExecContext(ctx, fmt.Sprintf("ALTER SCHEMA %s RENAME TO %s", buildName, schemaName))
+	if err != nil {
+		return fmt.Errorf("rename build schema %s to %s, %w", buildName, schemaName, err)
+	}
+
+	return nil
+}
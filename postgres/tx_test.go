@@ -0,0 +1,55 @@
+package postgrescontainer_test
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+
+	postgrescontainer "github.com/amidgo/containers/postgres"
+	goosemigrations "github.com/amidgo/containers/postgres/migrations/goose"
+	postgrescontainerrunner "github.com/amidgo/containers/postgres/runner"
+)
+
+func Test_TxForTesting(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	tx := postgrescontainer.TxForTesting(t,
+		postgrescontainerrunner.Reusable(),
+		goosemigrations.New(os.DirFS("./testdata/migrations")),
+		"INSERT INTO users (name) VALUES ('Dima')",
+	)
+
+	for _, name := range []string{"amidman", "amidgo"} {
+		t.Run(name, func(t *testing.T) {
+			postgrescontainer.SavepointForTesting(t, tx)
+
+			_, err := tx.ExecContext(ctx, "INSERT INTO users (name) VALUES ($1)", name)
+			if err != nil {
+				t.Fatalf("insert user %s, %s", name, err)
+			}
+
+			assertUsersCount(t, ctx, tx, 2)
+		})
+	}
+
+	assertUsersCount(t, ctx, tx, 1)
+}
+
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func assertUsersCount(t *testing.T, ctx context.Context, db queryRower, expected int) {
+	var count int
+
+	err := db.QueryRowContext(ctx, "SELECT count(*) FROM users").Scan(&count)
+	if err != nil {
+		t.Fatalf("count users, %s", err)
+	}
+
+	if count != expected {
+		t.Fatalf("expected %d users, actual %d", expected, count)
+	}
+}
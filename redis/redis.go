@@ -3,21 +3,32 @@ package containers
 import (
 	"context"
 	"fmt"
-	"log"
 	"os"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/amidgo/containers"
+	"github.com/amidgo/containers/internal/docker"
 	redis "github.com/redis/go-redis/v9"
+	"github.com/testcontainers/testcontainers-go"
 	rediscontainer "github.com/testcontainers/testcontainers-go/modules/redis"
 )
 
 func RunForTesting(t *testing.T, initial map[string]any) *redis.Client {
-	containers.SkipDisabled(t)
+	containers.SkipDisabled(t, containers.BackendRedis)
+	containers.RequireDocker(t)
 
-	redisClient, term, err := Run(initial)
+	since := time.Now()
+
+	redisClient, cnt, term, err := run(initial)
 	t.Cleanup(term)
 
+	if cnt != nil {
+		containers.DumpLogsOnFailure(t, since, cnt)
+		containers.KeepOnFailureCleanup(t, cnt, cnt.redisCliCommand)
+	}
+
 	if err != nil {
 		t.Fatalf("start redis container, err: %s", err)
 	}
@@ -26,6 +37,12 @@ func RunForTesting(t *testing.T, initial map[string]any) *redis.Client {
 }
 
 func Run(initial map[string]any) (redisClient *redis.Client, term func(), err error) {
+	redisClient, _, term, err = run(initial)
+
+	return redisClient, term, err
+}
+
+func run(initial map[string]any) (redisClient *redis.Client, cnt *container, term func(), err error) {
 	ctx := context.Background()
 
 	redisImage := "redis:6"
@@ -34,42 +51,88 @@ func Run(initial map[string]any) (redisClient *redis.Client, term func(), err er
 		redisImage = img
 	}
 
-	redisContainer, err := rediscontainer.Run(ctx, redisImage)
+	err = containers.DockerAvailable(ctx)
+	if err != nil {
+		return nil, nil, func() {}, err
+	}
+
+	customizers := []testcontainers.ContainerCustomizer{docker.WithSessionLabels()}
+
+	redisContainer, err := rediscontainer.Run(ctx, redisImage, customizers...)
 	if err != nil {
-		log.Fatalf("failed to start container: %s", err)
+		return nil, nil, func() {}, fmt.Errorf("run container, %w", err)
+	}
+
+	cnt = &container{
+		cnt:        redisContainer,
+		unregister: containers.RegisterCleanup(redisContainer),
 	}
 
 	term = func() {
-		if err := redisContainer.Terminate(ctx); err != nil {
-			log.Printf("failed to terminate container: %s", err)
+		if err := cnt.Terminate(ctx); err != nil {
+			containers.Logger().Error("failed to terminate container", "error", err)
 		}
 	}
 
 	addr, err := redisContainer.ConnectionString(ctx)
 	if err != nil {
-		return nil, term, fmt.Errorf("get connection string, %w", err)
+		return nil, cnt, term, fmt.Errorf("get connection string, %w", err)
 	}
 
 	opts, err := redis.ParseURL(addr)
 	if err != nil {
-		return nil, term, fmt.Errorf("parse url: %s, %w", addr, err)
+		return nil, cnt, term, fmt.Errorf("parse url: %s, %w", addr, err)
 	}
 
 	client := redis.NewClient(opts)
 
 	err = client.Ping(ctx).Err()
 	if err != nil {
-		return nil, term, fmt.Errorf("ping client, %w", err)
+		return nil, cnt, term, fmt.Errorf("ping client, %w", err)
 	}
 
 	if initial != nil {
 		err := initializeRedis(ctx, client, initial)
 		if err != nil {
-			return nil, term, fmt.Errorf("initialize redis, %w", err)
+			return nil, cnt, term, fmt.Errorf("initialize redis, %w", err)
 		}
 	}
 
-	return client, term, nil
+	return client, cnt, term, nil
+}
+
+type container struct {
+	cnt        *rediscontainer.RedisContainer
+	kept       atomic.Bool
+	unregister func()
+}
+
+func (c *container) ContainerID() string {
+	return c.cnt.GetContainerID()
+}
+
+func (c *container) Keep() {
+	c.kept.Store(true)
+	c.unregister()
+}
+
+func (c *container) Terminate(ctx context.Context) error {
+	if c.kept.Load() {
+		return nil
+	}
+
+	c.unregister()
+
+	return c.cnt.Terminate(ctx)
+}
+
+func (c *container) redisCliCommand(ctx context.Context) (string, error) {
+	addr, err := c.cnt.ConnectionString(ctx)
+	if err != nil {
+		return "", fmt.Errorf("get connection string, %w", err)
+	}
+
+	return "redis-cli -u " + addr, nil
 }
 
 func initializeRedis(ctx context.Context, client *redis.Client, initial map[string]any) error {
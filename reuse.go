@@ -3,11 +3,18 @@ package containers
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
+	"slices"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
+type Terminater interface {
+	Terminate(ctx context.Context) error
+}
+
 type reuseCommand uint8
 
 const (
@@ -15,59 +22,162 @@ const (
 	reuseCommandExit
 )
 
-type reuseContainerRequest struct {
+type reuseContainerRequest[T Terminater] struct {
 	reuseCmd reuseCommand
 	ctx      context.Context
+	lease    *Lease[T]
+	respCh   chan reuseContainerResponse[T]
+}
+
+type reuseContainerResponse[T Terminater] struct {
+	lease *Lease[T]
+	err   error
 }
 
-type reuseContainerResponse struct {
-	cnt any
-	err error
+type createContainerResult[T Terminater] struct {
+	cnt        T
+	generation int
+	createdAt  time.Time
+	err        error
+	duration   time.Duration
+}
+
+type CreateContainerFunc[T Terminater] func(ctx context.Context) (T, error)
+
+type ReusableDaemonOption func(o *reusableDaemonOptions)
+
+type reusableDaemonOptions struct {
+	healthCheck         bool
+	healthCheckInterval time.Duration
+	maxUses             int
+	maxLifetime         time.Duration
+	maxActiveUsers      int
+	observers           []Observer
+	logger              *slog.Logger
 }
 
-type CreateContainerFunc func(ctx context.Context) (any, error)
+// reusableInstance is a created container with its users,
+// retired instances are not handed out and are terminated when the last user exits.
+type reusableInstance[T Terminater] struct {
+	cnt T
+	// generation of the shared container, zero without sharing
+	generation int
+	users      int
+	uses       int
+	createdAt  time.Time
+	terminated bool
+}
 
-type ReusableDaemon struct {
-	activeUsers  int
-	cnt          any
+type ReusableDaemon[T Terminater] struct {
+	current  *reusableInstance[T]
+	retired  []*reusableInstance[T]
+	creating bool
+	checking bool
+	waiters  []reuseContainerRequest[T]
+	leasesMu sync.Mutex
+	leases   map[*Lease[T]]struct{}
+	// load is number of leases held and requested, used by the pool to choose the least loaded daemon
+	load         atomic.Int64
+	idleCh       <-chan time.Time
 	waitDuration time.Duration
 	mainCtx      context.Context
 	termCtx      context.Context
 
-	reqCh  chan reuseContainerRequest
-	respCh chan reuseContainerResponse
+	reqCh     chan reuseContainerRequest[T]
+	createdCh chan createContainerResult[T]
+	checkedCh chan healthCheckResult[T]
 
-	ccf CreateContainerFunc
+	// detached tracks terminations running outside of the loop, shutdown waits for them
+	detached sync.WaitGroup
+	// leftGenerations are shared generations retired or discarded by this daemon, they are never attached again
+	leftGenerations []int
+
+	ccf            CreateContainerFunc[T]
+	shared         *sharedContainer[T]
+	healthCheck    bool
+	maxUses        int
+	maxLifetime    time.Duration
+	maxActiveUsers int
+	observers      []Observer
+	log            *slog.Logger
+}
+
+func RunReusableDaemon[T Terminater](
+	ctx context.Context,
+	waitDuration time.Duration,
+	ccf CreateContainerFunc[T],
+	opts ...ReusableDaemonOption,
+) *ReusableDaemon[T] {
+	return runReusableDaemon(ctx, waitDuration, ccf, nil, opts...)
 }
 
-func RunReusableDaemon(
+func runReusableDaemon[T Terminater](
 	ctx context.Context,
 	waitDuration time.Duration,
-	ccf CreateContainerFunc,
-) *ReusableDaemon {
+	ccf CreateContainerFunc[T],
+	sharing *Sharing[T],
+	opts ...ReusableDaemonOption,
+) *ReusableDaemon[T] {
+	options := reusableDaemonOptions{}
+
+	for _, op := range opts {
+		op(&options)
+	}
+
 	termCtx, cancel := context.WithCancel(context.Background())
 
-	daemon := &ReusableDaemon{
-		activeUsers:  0,
-		cnt:          nil,
-		waitDuration: waitDuration,
-		mainCtx:      ctx,
-		termCtx:      termCtx,
-		reqCh:        make(chan reuseContainerRequest),
-		respCh:       make(chan reuseContainerResponse),
-		ccf:          ccf,
+	daemon := &ReusableDaemon[T]{
+		waitDuration:   waitDuration,
+		mainCtx:        ctx,
+		termCtx:        termCtx,
+		leases:         make(map[*Lease[T]]struct{}),
+		reqCh:          make(chan reuseContainerRequest[T]),
+		createdCh:      make(chan createContainerResult[T]),
+		checkedCh:      make(chan healthCheckResult[T]),
+		ccf:            ccf,
+		healthCheck:    options.healthCheck,
+		maxUses:        options.maxUses,
+		maxLifetime:    options.maxLifetime,
+		maxActiveUsers: options.maxActiveUsers,
+		observers:      options.observers,
+		log:            options.logger,
+	}
+
+	if summaryEnabled() {
+		daemon.observers = append(daemon.observers, runSummary)
+	}
+
+	if sharing != nil {
+		daemon.shared = newSharedContainer(sharing.Key, sharing.Sharer, daemon.logger)
+	}
+
+	var healthCheckCh <-chan time.Time
+
+	if options.healthCheck && options.healthCheckInterval > 0 {
+		ticker := time.NewTicker(options.healthCheckInterval)
+		healthCheckCh = ticker.C
+
+		context.AfterFunc(termCtx, ticker.Stop)
 	}
 
 	go func() {
 		for {
 			select {
 			case <-ctx.Done():
-				daemon.clearContainer(termCtx)
+				daemon.shutdown()
 				cancel()
 
 				return
 			case req := <-daemon.reqCh:
-				daemon.handleReuseCommand(req.ctx, req.reuseCmd)
+				daemon.handleReuseCommand(req)
+			case res := <-daemon.createdCh:
+				daemon.handleCreatedContainer(res)
+			case res := <-daemon.checkedCh:
+				daemon.handleHealthChecked(res)
+			case <-daemon.idleCh:
+				daemon.handleIdle()
+			case <-healthCheckCh:
+				daemon.handleHealthCheck()
 			}
 		}
 	}()
@@ -75,118 +185,498 @@ func RunReusableDaemon(
 	return daemon
 }
 
-func (d *ReusableDaemon) Done() <-chan struct{} {
+func (d *ReusableDaemon[T]) Done() <-chan struct{} {
 	return d.termCtx.Done()
 }
 
-func (d *ReusableDaemon) Enter(ctx context.Context) (any, error) {
+// Leases returns info about leases which are not released yet, oldest first.
+func (d *ReusableDaemon[T]) Leases() []LeaseInfo {
+	d.leasesMu.Lock()
+	defer d.leasesMu.Unlock()
+
+	infos := make([]LeaseInfo, 0, len(d.leases))
+
+	for lease := range d.leases {
+		infos = append(infos, lease.info)
+	}
+
+	slices.SortFunc(infos, func(a, b LeaseInfo) int {
+		return a.AcquiredAt.Compare(b.AcquiredAt)
+	})
+
+	return infos
+}
+
+// Enter stops waiting for the container when ctx is done,
+// creation of the container continues for other callers.
+// Returned Lease must be released when the container is not needed anymore.
+func (d *ReusableDaemon[T]) Enter(ctx context.Context) (*Lease[T], error) {
+	d.load.Add(1)
+
+	return d.enter(ctx, newLease(ctx, d))
+}
+
+// Warmup creates the container in advance, the idle timer starts once the container is created,
+// so the warm container is terminated if no test enters within the wait duration.
+func (d *ReusableDaemon[T]) Warmup(ctx context.Context) error {
+	lease := newLease(ctx, d)
+	lease.warmup = true
+
+	d.load.Add(1)
+
+	lease, err := d.enter(ctx, lease)
+	if err != nil {
+		return err
+	}
+
+	lease.Release()
+
+	return nil
+}
+
+// enter expects load is already increased, it is decreased back if the lease isn't handed out.
+func (d *ReusableDaemon[T]) enter(ctx context.Context, lease *Lease[T]) (*Lease[T], error) {
+	if d.mainCtx.Err() != nil {
+		d.load.Add(-1)
+
+		return nil, fmt.Errorf("root ctx is done, %w", context.Cause(d.mainCtx))
+	}
+
+	respCh := make(chan reuseContainerResponse[T], 1)
+
 	select {
 	case <-d.mainCtx.Done():
+		d.load.Add(-1)
+
 		return nil, fmt.Errorf("root ctx is done, %w", context.Cause(d.mainCtx))
-	case d.reqCh <- reuseContainerRequest{
+	case <-ctx.Done():
+		d.load.Add(-1)
+
+		return nil, fmt.Errorf("enter to daemon, %w", context.Cause(ctx))
+	case d.reqCh <- reuseContainerRequest[T]{
 		ctx:      ctx,
 		reuseCmd: reuseCommandEnter,
+		lease:    lease,
+		respCh:   respCh,
 	}:
-		resp := <-d.respCh
+	}
+
+	select {
+	case resp := <-respCh:
+		if resp.err != nil {
+			d.load.Add(-1)
+
+			return nil, resp.err
+		}
+
+		logSlotWait(ctx, resp.lease.info)
 
-		return resp.cnt, resp.err
+		return resp.lease, nil
+	case <-ctx.Done():
+		go d.abandon(respCh)
+
+		return nil, fmt.Errorf("wait for container, %w", context.Cause(ctx))
 	}
 }
 
-func (d *ReusableDaemon) Exit() {
+// abandon releases the lease if it was handed out after the caller stopped waiting for it.
+func (d *ReusableDaemon[T]) abandon(respCh chan reuseContainerResponse[T]) {
+	resp := <-respCh
+	if resp.err != nil {
+		d.load.Add(-1)
+
+		return
+	}
+
+	resp.lease.Release()
+}
+
+func (d *ReusableDaemon[T]) exit(lease *Lease[T]) {
+	defer d.load.Add(-1)
+
+	respCh := make(chan reuseContainerResponse[T], 1)
+
 	select {
 	case <-d.mainCtx.Done():
 		<-d.termCtx.Done()
-	case d.reqCh <- reuseContainerRequest{
+	case d.reqCh <- reuseContainerRequest[T]{
 		ctx:      context.Background(),
 		reuseCmd: reuseCommandExit,
+		lease:    lease,
+		respCh:   respCh,
 	}:
-		<-d.respCh
+		<-respCh
 	}
 }
 
-func (d *ReusableDaemon) handleReuseCommand(ctx context.Context, reuseCmd reuseCommand) {
-	switch reuseCmd {
+func (d *ReusableDaemon[T]) handleReuseCommand(req reuseContainerRequest[T]) {
+	switch req.reuseCmd {
 	case reuseCommandEnter:
-		d.activeUsers++
+		d.handleEnter(req)
 	case reuseCommandExit:
-		d.activeUsers--
+		d.handleExit(req)
 	default:
-		panic("invalid reuse command received: " + strconv.FormatUint(uint64(reuseCmd), 10))
+		panic("invalid reuse command received: " + strconv.FormatUint(uint64(req.reuseCmd), 10))
 	}
+}
 
-	switch {
-	case d.activeUsers > 0:
-		d.handlePositiveActiveUsers(ctx)
-	case d.activeUsers == 0:
-		d.handleZeroActiveUsers(ctx)
-	case d.activeUsers <= 0:
-		panic("reuse container term func called twice, negative amount of active users")
+func (d *ReusableDaemon[T]) handleEnter(req reuseContainerRequest[T]) {
+	if d.mainCtx.Err() != nil {
+		req.respCh <- reuseContainerResponse[T]{
+			err: fmt.Errorf("root ctx is done, %w", context.Cause(d.mainCtx)),
+		}
+
+		return
 	}
+
+	d.idleCh = nil
+
+	if d.current != nil && d.expired(d.current) {
+		d.retireCurrent(nil)
+	}
+
+	// shared container may be retired by another process, so it is checked like the health
+	if d.current != nil && (d.healthCheck || d.shared != nil) {
+		d.startHealthCheck()
+	}
+
+	d.waiters = append(d.waiters, req)
+
+	d.serveWaiters()
 }
 
-func (d *ReusableDaemon) handlePositiveActiveUsers(ctx context.Context) {
-	if d.cnt == nil {
-		cnt, err := d.ccf(ctx)
-		if err != nil {
-			d.respCh <- reuseContainerResponse{
-				err: fmt.Errorf("create new container, %w", err),
-			}
+func (d *ReusableDaemon[T]) startCreating() {
+	if d.creating {
+		return
+	}
 
-			return
+	d.creating = true
+
+	go d.runCreateContainer(slices.Clone(d.leftGenerations))
+}
+
+func (d *ReusableDaemon[T]) handOut(req reuseContainerRequest[T]) {
+	inst := d.current
+
+	inst.users++
+
+	if !req.lease.warmup {
+		inst.uses++
+	}
+
+	d.idleCh = nil
+
+	req.lease.cnt = inst.cnt
+	req.lease.instance = inst
+
+	if !req.lease.blockedAt.IsZero() {
+		req.lease.info.SlotWait = time.Since(req.lease.blockedAt)
+	}
+
+	req.lease.enteredAt = time.Now()
+
+	if !req.lease.warmup {
+		d.observe(EventEntered{TestName: req.lease.info.TestName, Wait: time.Since(req.lease.info.AcquiredAt)})
+	}
+
+	d.leasesMu.Lock()
+	d.leases[req.lease] = struct{}{}
+	d.leasesMu.Unlock()
+
+	respond := func() {
+		req.respCh <- reuseContainerResponse[T]{
+			lease: req.lease,
 		}
+	}
+
+	// the last use responds once the retirement is done, so other processes don't attach the used up container after it
+	if d.usedUp(inst) {
+		d.retireCurrent(respond)
+
+		return
+	}
+
+	respond()
+}
+
+// handleExit responds after the container left by its last user is terminated, the termination runs outside of the loop.
+func (d *ReusableDaemon[T]) handleExit(req reuseContainerRequest[T]) {
+	respond := func() { req.respCh <- reuseContainerResponse[T]{} }
+
+	d.leasesMu.Lock()
+	_, ok := d.leases[req.lease]
+	delete(d.leases, req.lease)
+	d.leasesMu.Unlock()
+
+	if !ok {
+		respond()
+
+		return
+	}
+
+	if !req.lease.warmup {
+		d.observe(EventExited{TestName: req.lease.info.TestName, Held: time.Since(req.lease.enteredAt)})
+	}
 
-		if cnt == nil {
-			panic("nil container returned")
+	inst := req.lease.instance
+
+	// kept container may be changed by the failed test, so it isn't handed out anymore
+	if req.lease.kept.Load() && inst == d.current {
+		d.retireCurrent(nil)
+		d.serveWaiters()
+	}
+
+	inst.users--
+
+	if inst != d.current {
+		if inst.users == 0 {
+			d.removeRetired(inst)
+			d.terminateInstance(inst, respond)
+
+			return
 		}
 
-		d.cnt = cnt
+		respond()
+
+		return
 	}
 
-	d.respCh <- reuseContainerResponse{
-		cnt: d.cnt,
+	d.serveWaiters()
+
+	if d.current == inst && inst.users == 0 {
+		d.startIdleTimer()
 	}
+
+	respond()
 }
 
-func (d *ReusableDaemon) handleZeroActiveUsers(ctx context.Context) {
-	select {
-	case <-time.After(d.waitDuration):
-		d.clearContainer(ctx)
-		d.respCh <- reuseContainerResponse{}
-	case req := <-d.reqCh:
-		switch req.reuseCmd {
-		case reuseCommandEnter:
-			d.activeUsers++
-			d.respCh <- reuseContainerResponse{
-				cnt: d.cnt,
+func (d *ReusableDaemon[T]) startIdleTimer() {
+	d.idleCh = time.After(d.waitDuration)
+
+	d.observe(EventIdleTimerStarted{Duration: d.waitDuration})
+}
+
+// runCreateContainer creates the container outside of the daemon loop with the daemon ctx,
+// so callers giving up waiting don't abort it.
+func (d *ReusableDaemon[T]) runCreateContainer(skipGenerations []int) {
+	start := time.Now()
+
+	res := d.createContainer(d.mainCtx, skipGenerations)
+
+	if res.err == nil && any(res.cnt) == nil {
+		panic("nil container returned")
+	}
+
+	res.duration = time.Since(start)
+
+	d.createdCh <- res
+}
+
+func (d *ReusableDaemon[T]) handleCreatedContainer(res createContainerResult[T]) {
+	d.creating = false
+
+	if res.err != nil {
+		d.observe(EventCreateFailed{Duration: res.duration, Err: res.err})
+
+		d.failWaiters(fmt.Errorf("create new container, %w", res.err))
+
+		return
+	}
+
+	d.observe(EventCreated{Duration: res.duration})
+
+	d.current = newReusableInstance(res)
+
+	d.serveWaiters()
+}
+
+// serveWaiters hands out the current container to waiters in order of enter,
+// waiters wait while the container is being created, health checked or all its slots are taken.
+func (d *ReusableDaemon[T]) serveWaiters() {
+	for len(d.waiters) > 0 {
+		req := d.waiters[0]
+
+		if req.ctx.Err() != nil {
+			d.waiters = d.waiters[1:]
+
+			req.respCh <- reuseContainerResponse[T]{
+				err: fmt.Errorf("wait for container, %w", context.Cause(req.ctx)),
 			}
-			d.respCh <- reuseContainerResponse{
-				cnt: d.cnt,
+
+			continue
+		}
+
+		if d.current == nil {
+			d.startCreating()
+
+			return
+		}
+
+		if d.checking {
+			return
+		}
+
+		if d.full(d.current) {
+			if req.lease.blockedAt.IsZero() {
+				req.lease.blockedAt = time.Now()
 			}
-		case reuseCommandExit:
-			panic("unexpected exit command in handleZeroActiveUsers")
-		default:
-			panic("invalid reuse command received: " + strconv.FormatUint(uint64(req.reuseCmd), 10))
+
+			return
 		}
+
+		d.waiters = d.waiters[1:]
+
+		d.handOut(req)
 	}
 }
 
-func (d *ReusableDaemon) clearContainer(ctx context.Context) {
-	if d.cnt == nil {
+// failWaiters responds err to every caller waiting for the container.
+func (d *ReusableDaemon[T]) failWaiters(err error) {
+	for _, req := range d.waiters {
+		req.respCh <- reuseContainerResponse[T]{
+			err: err,
+		}
+	}
+
+	d.waiters = nil
+}
+
+func (d *ReusableDaemon[T]) handleIdle() {
+	d.idleCh = nil
+
+	if d.current == nil || d.current.users > 0 {
 		return
 	}
 
-	type Terminater interface {
-		Terminate(ctx context.Context) error
+	d.clearContainer()
+}
+
+// shutdown terminates all containers and waits for terminations started by the loop.
+func (d *ReusableDaemon[T]) shutdown() {
+	if d.checking {
+		<-d.checkedCh
+
+		d.checking = false
+	}
+
+	if d.creating {
+		res := <-d.createdCh
+
+		d.creating = false
+
+		if res.err == nil {
+			d.current = newReusableInstance(res)
+		}
+	}
+
+	d.failWaiters(fmt.Errorf("root ctx is done, %w", context.Cause(d.mainCtx)))
+
+	d.leasesMu.Lock()
+
+	for lease := range d.leases {
+		lease.reportNotReleased()
+	}
+
+	clear(d.leases)
+
+	d.leasesMu.Unlock()
+
+	d.clearContainer()
+
+	for _, inst := range d.retired {
+		d.terminateInstance(inst, nil)
+	}
+
+	d.retired = nil
+
+	d.detached.Wait()
+}
+
+func (d *ReusableDaemon[T]) createContainer(ctx context.Context, skipGenerations []int) createContainerResult[T] {
+	if d.shared != nil {
+		entered, err := d.shared.enter(ctx, d.ccf, skipGenerations)
+
+		return createContainerResult[T]{
+			cnt:        entered.cnt,
+			generation: entered.generation,
+			createdAt:  entered.createdAt,
+			err:        err,
+		}
+	}
+
+	cnt, err := d.ccf(ctx)
+
+	return createContainerResult[T]{
+		cnt:       cnt,
+		createdAt: time.Now(),
+		err:       err,
+	}
+}
+
+func newReusableInstance[T Terminater](res createContainerResult[T]) *reusableInstance[T] {
+	return &reusableInstance[T]{
+		cnt:        res.cnt,
+		generation: res.generation,
+		createdAt:  res.createdAt,
+	}
+}
+
+// terminateInstance marks inst terminated and terminates it outside of the loop, done is called after that.
+func (d *ReusableDaemon[T]) terminateInstance(inst *reusableInstance[T], done func()) {
+	if inst.terminated {
+		if done != nil {
+			done()
+		}
+
+		return
 	}
 
-	trm, ok := d.cnt.(Terminater)
-	if ok {
-		err := trm.Terminate(ctx)
-		if err != nil {
-			log.Printf("failed terminate container, %s", err)
+	inst.terminated = true
+
+	d.detach(func() {
+		if done != nil {
+			defer done()
 		}
+
+		d.terminate(inst)
+	})
+}
+
+// terminate exits shared container or terminates own one, it is called outside of the loop.
+func (d *ReusableDaemon[T]) terminate(inst *reusableInstance[T]) {
+	var err error
+
+	if d.shared != nil {
+		err = d.shared.exit(d.termCtx, inst.generation, inst.cnt)
+	} else {
+		err = inst.cnt.Terminate(d.termCtx)
 	}
 
-	d.cnt = nil
+	d.observe(EventTerminated{Lifetime: time.Since(inst.createdAt), Err: err})
+
+	if err != nil {
+		d.logger().Error("failed terminate container", "error", err)
+	}
+}
+
+// detach runs f outside of the loop, so slow terminations and shared state locks don't block enters and exits.
+func (d *ReusableDaemon[T]) detach(f func()) {
+	d.detached.Add(1)
+
+	go func() {
+		defer d.detached.Done()
+
+		f()
+	}()
+}
+
+// clearContainer terminates the current container, next enter creates new one.
+func (d *ReusableDaemon[T]) clearContainer() {
+	if d.current == nil {
+		return
+	}
+
+	d.terminateInstance(d.current, nil)
+
+	d.current = nil
 }
@@ -0,0 +1,94 @@
+package containers
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Deprecated: use CreateContainerFunc with a concrete container type.
+type CreateAnyContainerFunc func(ctx context.Context) (any, error)
+
+// AnyContainer wraps containers of unknown type,
+// Terminate is called only if the wrapped value implements Terminater.
+type AnyContainer struct {
+	Value any
+}
+
+func (a AnyContainer) Terminate(ctx context.Context) error {
+	trm, ok := a.Value.(Terminater)
+	if !ok {
+		return nil
+	}
+
+	return trm.Terminate(ctx)
+}
+
+// Deprecated: use ReusableDaemon with a concrete container type.
+type AnyReusableDaemon struct {
+	daemon *ReusableDaemon[AnyContainer]
+
+	mu     sync.Mutex
+	leases []*Lease[AnyContainer]
+}
+
+// Deprecated: use RunReusableDaemon with a concrete container type.
+func RunAnyReusableDaemon(
+	ctx context.Context,
+	waitDuration time.Duration,
+	ccf CreateAnyContainerFunc,
+) *AnyReusableDaemon {
+	anyCcf := func(ctx context.Context) (AnyContainer, error) {
+		cnt, err := ccf(ctx)
+		if err != nil {
+			return AnyContainer{}, err
+		}
+
+		if cnt == nil {
+			panic("nil container returned")
+		}
+
+		return AnyContainer{Value: cnt}, nil
+	}
+
+	return &AnyReusableDaemon{
+		daemon: RunReusableDaemon(ctx, waitDuration, anyCcf),
+	}
+}
+
+func (d *AnyReusableDaemon) Done() <-chan struct{} {
+	return d.daemon.Done()
+}
+
+func (d *AnyReusableDaemon) Enter(ctx context.Context) (any, error) {
+	lease, err := d.daemon.Enter(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	d.leases = append(d.leases, lease)
+	d.mu.Unlock()
+
+	return lease.Container().Value, nil
+}
+
+// Exit releases one of the leases acquired by Enter, calls without acquired leases are logged and ignored.
+func (d *AnyReusableDaemon) Exit() {
+	d.mu.Lock()
+
+	if len(d.leases) == 0 {
+		d.mu.Unlock()
+
+		d.daemon.logger().Warn("reusable daemon exit called without entered container, the call is ignored")
+
+		return
+	}
+
+	lease := d.leases[len(d.leases)-1]
+	d.leases = d.leases[:len(d.leases)-1]
+
+	d.mu.Unlock()
+
+	lease.Release()
+}
@@ -0,0 +1,100 @@
+package containers_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/amidgo/containers"
+)
+
+func Test_AnyReusableDaemon(t *testing.T) {
+	t.Parallel()
+
+	t.Run("terminater value", anyReusableDaemonTerminaterValue)
+	t.Run("plain value", anyReusableDaemonPlainValue)
+	t.Run("exit twice", anyReusableDaemonExitTwice)
+}
+
+func anyReusableDaemonTerminaterValue(t *testing.T) {
+	t.Parallel()
+
+	cnt := newMockTerminater(t)
+
+	ccf := containers.CreateAnyContainerFunc(func(ctx context.Context) (any, error) {
+		return cnt, nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	daemon := containers.RunAnyReusableDaemon(ctx, time.Second, ccf)
+
+	enterCnt, err := daemon.Enter(ctx)
+	if err != nil {
+		t.Fatalf("enter to daemon, expected no error, actual %s", err)
+	}
+
+	if enterCnt != cnt {
+		t.Fatalf("enter to daemon, expected %+v, actual %+v", cnt, enterCnt)
+	}
+
+	daemon.Exit()
+
+	cancel()
+
+	<-daemon.Done()
+}
+
+func anyReusableDaemonPlainValue(t *testing.T) {
+	t.Parallel()
+
+	ccf := containers.CreateAnyContainerFunc(func(ctx context.Context) (any, error) {
+		return true, nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	daemon := containers.RunAnyReusableDaemon(ctx, time.Second, ccf)
+
+	enterCnt, err := daemon.Enter(ctx)
+	if err != nil {
+		t.Fatalf("enter to daemon, expected no error, actual %s", err)
+	}
+
+	if enterCnt != true {
+		t.Fatalf("enter to daemon, expected true, actual %+v", enterCnt)
+	}
+
+	daemon.Exit()
+
+	cancel()
+
+	<-daemon.Done()
+}
+
+func anyReusableDaemonExitTwice(t *testing.T) {
+	t.Parallel()
+
+	ccf := containers.CreateAnyContainerFunc(func(ctx context.Context) (any, error) {
+		return true, nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	daemon := containers.RunAnyReusableDaemon(ctx, time.Second, ccf)
+
+	_, err := daemon.Enter(ctx)
+	if err != nil {
+		t.Fatalf("enter to daemon, expected no error, actual %s", err)
+	}
+
+	daemon.Exit()
+	daemon.Exit()
+
+	cancel()
+
+	<-daemon.Done()
+}
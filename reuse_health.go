@@ -0,0 +1,132 @@
+package containers
+
+import (
+	"context"
+	"time"
+)
+
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+const healthCheckTimeout = 5 * time.Second
+
+type healthCheckResult[T Terminater] struct {
+	inst *reusableInstance[T]
+	err  error
+	// retired is set if the shared container is retired by another process
+	retired bool
+}
+
+// WithHealthCheck makes daemon ping containers implementing Pinger before handing them out on Enter,
+// and every interval while the container has active users if interval is positive.
+// Ping runs outside of the daemon loop, callers entering during the ping wait for its result.
+// Unhealthy containers are terminated and recreated by CreateContainerFunc.
+func WithHealthCheck(interval time.Duration) ReusableDaemonOption {
+	return func(o *reusableDaemonOptions) {
+		o.healthCheck = true
+		o.healthCheckInterval = interval
+	}
+}
+
+func (d *ReusableDaemon[T]) handleHealthCheck() {
+	if d.current == nil || d.current.users == 0 {
+		return
+	}
+
+	d.startHealthCheck()
+}
+
+// startHealthCheck pings the current container with the daemon ctx, one ping at a time.
+func (d *ReusableDaemon[T]) startHealthCheck() {
+	if d.checking {
+		return
+	}
+
+	d.checking = true
+
+	inst := d.current
+
+	go func() {
+		d.checkedCh <- d.checkInstance(inst)
+	}()
+}
+
+func (d *ReusableDaemon[T]) checkInstance(inst *reusableInstance[T]) healthCheckResult[T] {
+	res := healthCheckResult[T]{inst: inst}
+
+	if d.healthCheck {
+		res.err = checkHealth(d.mainCtx, inst.cnt)
+	}
+
+	if res.err != nil || d.shared == nil {
+		return res
+	}
+
+	retired, err := d.shared.retired(d.mainCtx, inst.generation)
+	if err != nil {
+		d.logger().Error("failed check shared container is retired", "error", err)
+	}
+
+	res.retired = retired
+
+	return res
+}
+
+func (d *ReusableDaemon[T]) handleHealthChecked(res healthCheckResult[T]) {
+	d.checking = false
+
+	// the container may be retired or terminated while it was pinged
+	if res.inst == d.current {
+		switch {
+		case res.err != nil:
+			d.clearUnhealthyContainer(res.err)
+		case res.retired:
+			d.retireCurrent(nil)
+		}
+	}
+
+	d.serveWaiters()
+}
+
+// clearUnhealthyContainer detaches the current container in the loop and terminates it outside of it,
+// shared container is discarded for all processes.
+func (d *ReusableDaemon[T]) clearUnhealthyContainer(err error) {
+	d.logger().Warn("reusable container is unhealthy, terminate it and create new one on next enter", "error", err)
+
+	if d.shared == nil {
+		d.clearContainer()
+
+		return
+	}
+
+	inst := d.current
+	inst.terminated = true
+
+	d.current = nil
+	d.idleCh = nil
+
+	d.leftGenerations = append(d.leftGenerations, inst.generation)
+
+	d.detach(func() {
+		err := d.shared.discard(d.termCtx, inst.generation, inst.cnt)
+
+		d.observe(EventTerminated{Lifetime: time.Since(inst.createdAt), Err: err})
+
+		if err != nil {
+			d.logger().Error("failed terminate container", "error", err)
+		}
+	})
+}
+
+func checkHealth[T Terminater](ctx context.Context, cnt T) error {
+	pinger, ok := any(cnt).(Pinger)
+	if !ok {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	return pinger.Ping(ctx)
+}
@@ -0,0 +1,190 @@
+package containers_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/amidgo/containers"
+)
+
+type pingContainer struct {
+	id         int64
+	healthy    atomic.Bool
+	terminated atomic.Bool
+	// block makes Ping signal started and wait until block is closed
+	block   chan struct{}
+	started chan struct{}
+}
+
+func (p *pingContainer) Ping(context.Context) error {
+	if p.block != nil {
+		close(p.started)
+		<-p.block
+	}
+
+	if !p.healthy.Load() {
+		return errors.New("container is dead")
+	}
+
+	return nil
+}
+
+func (p *pingContainer) Terminate(context.Context) error {
+	p.terminated.Store(true)
+
+	return nil
+}
+
+func pingContainerCcf(created *atomic.Int64) containers.CreateContainerFunc[*pingContainer] {
+	return func(context.Context) (*pingContainer, error) {
+		cnt := &pingContainer{id: created.Add(1)}
+		cnt.healthy.Store(true)
+
+		return cnt, nil
+	}
+}
+
+func Test_ReuseDaemon_HealthCheck_Enter(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	created := &atomic.Int64{}
+
+	daemon := containers.RunReusableDaemon(ctx,
+		time.Minute,
+		pingContainerCcf(created),
+		containers.WithHealthCheck(0),
+	)
+
+	firstLease, err := daemon.Enter(ctx)
+	if err != nil {
+		t.Fatalf("first enter, expected no error, actual %s", err)
+	}
+
+	t.Cleanup(firstLease.Release)
+
+	first := firstLease.Container()
+	first.healthy.Store(false)
+
+	secondLease, err := daemon.Enter(ctx)
+	if err != nil {
+		t.Fatalf("second enter, expected no error, actual %s", err)
+	}
+
+	t.Cleanup(secondLease.Release)
+
+	second := secondLease.Container()
+
+	if second.id != 2 {
+		t.Fatalf("expected recreated container with id 2, actual %d", second.id)
+	}
+
+	// unhealthy container is terminated outside of the daemon loop
+	if !waitFor(time.Second, first.terminated.Load) {
+		t.Fatal("unhealthy container not terminated")
+	}
+}
+
+func Test_ReuseDaemon_HealthCheck_Interval(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	created := &atomic.Int64{}
+
+	daemon := containers.RunReusableDaemon(ctx,
+		time.Minute,
+		pingContainerCcf(created),
+		containers.WithHealthCheck(time.Millisecond),
+	)
+
+	lease, err := daemon.Enter(ctx)
+	if err != nil {
+		t.Fatalf("enter, expected no error, actual %s", err)
+	}
+
+	t.Cleanup(lease.Release)
+
+	cnt := lease.Container()
+	cnt.healthy.Store(false)
+
+	deadline := time.After(time.Second)
+
+	for !cnt.terminated.Load() {
+		select {
+		case <-deadline:
+			t.Fatal("unhealthy container not terminated by periodic health check")
+		case <-time.After(time.Millisecond):
+		}
+	}
+
+	if created.Load() != 1 {
+		t.Fatalf("expected no new container before next enter, actual created %d", created.Load())
+	}
+}
+
+func Test_ReuseDaemon_HealthCheck_SlowPing(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	created := &atomic.Int64{}
+
+	daemon := containers.RunReusableDaemon(ctx,
+		time.Minute,
+		pingContainerCcf(created),
+		containers.WithHealthCheck(0),
+	)
+
+	firstLease, err := daemon.Enter(ctx)
+	if err != nil {
+		t.Fatalf("first enter, expected no error, actual %s", err)
+	}
+
+	unblock := make(chan struct{})
+	started := make(chan struct{})
+
+	first := firstLease.Container()
+	first.block = unblock
+	first.started = started
+
+	secondEntered := make(chan error, 1)
+
+	go func() {
+		lease, err := daemon.Enter(ctx)
+		if err == nil {
+			lease.Release()
+		}
+
+		secondEntered <- err
+	}()
+
+	<-started
+
+	released := make(chan struct{})
+
+	go func() {
+		firstLease.Release()
+		close(released)
+	}()
+
+	select {
+	case <-released:
+	case <-time.After(time.Second):
+		t.Fatal("release is blocked by slow ping of another enter")
+	}
+
+	close(unblock)
+
+	err = <-secondEntered
+	if err != nil {
+		t.Fatalf("second enter, expected no error, actual %s", err)
+	}
+}
@@ -0,0 +1,13 @@
+package containers
+
+// WithMaxActiveUsers limits number of leases of the container held at the same time,
+// Enter blocks until one of the leases is released or ctx is done.
+func WithMaxActiveUsers(n int) ReusableDaemonOption {
+	return func(o *reusableDaemonOptions) {
+		o.maxActiveUsers = n
+	}
+}
+
+func (d *ReusableDaemon[T]) full(inst *reusableInstance[T]) bool {
+	return d.maxActiveUsers > 0 && inst.users >= d.maxActiveUsers
+}
@@ -0,0 +1,53 @@
+package containers_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/amidgo/containers"
+)
+
+func Test_ReuseDaemon_MaxActiveUsers(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	ccf := containers.CreateContainerFunc[noopContainer](func(context.Context) (noopContainer, error) {
+		return noopContainer{}, nil
+	})
+
+	daemon := containers.RunReusableDaemon(ctx, time.Minute, ccf, containers.WithMaxActiveUsers(1))
+
+	first, err := daemon.Enter(ctx)
+	if err != nil {
+		t.Fatalf("first enter, expected no error, actual %s", err)
+	}
+
+	timeoutCtx, timeoutCancel := context.WithTimeout(ctx, time.Millisecond*10)
+	defer timeoutCancel()
+
+	_, err = daemon.Enter(timeoutCtx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected enter blocked until ctx is done, actual %v", err)
+	}
+
+	go func() {
+		<-time.After(time.Millisecond * 20)
+
+		first.Release()
+	}()
+
+	second, err := daemon.Enter(containers.ContextWithTest(ctx, t))
+	if err != nil {
+		t.Fatalf("second enter, expected no error, actual %s", err)
+	}
+
+	defer second.Release()
+
+	if second.Info().SlotWait <= 0 {
+		t.Fatal("expected slot wait is reported")
+	}
+}
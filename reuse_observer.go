@@ -0,0 +1,134 @@
+package containers
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Event is one of EventCreated, EventCreateFailed, EventEntered, EventExited, EventIdleTimerStarted, EventTerminated.
+type Event interface {
+	reusableEvent()
+}
+
+type EventCreated struct {
+	Duration time.Duration
+}
+
+type EventCreateFailed struct {
+	Duration time.Duration
+	Err      error
+}
+
+type EventEntered struct {
+	TestName string
+	Wait     time.Duration
+}
+
+type EventExited struct {
+	TestName string
+	Held     time.Duration
+}
+
+type EventIdleTimerStarted struct {
+	Duration time.Duration
+}
+
+type EventTerminated struct {
+	Lifetime time.Duration
+	Err      error
+}
+
+func (EventCreated) reusableEvent()          {}
+func (EventCreateFailed) reusableEvent()     {}
+func (EventEntered) reusableEvent()          {}
+func (EventExited) reusableEvent()           {}
+func (EventIdleTimerStarted) reusableEvent() {}
+func (EventTerminated) reusableEvent()       {}
+
+// Observer receives events of daemons, Observe is called concurrently by daemons sharing the observer.
+type Observer interface {
+	Observe(event Event)
+}
+
+type ObserverFunc func(event Event)
+
+func (f ObserverFunc) Observe(event Event) {
+	f(event)
+}
+
+func WithObserver(observer Observer) ReusableDaemonOption {
+	return func(o *reusableDaemonOptions) {
+		o.observers = append(o.observers, observer)
+	}
+}
+
+func (d *ReusableDaemon[T]) observe(event Event) {
+	for _, observer := range d.observers {
+		observer.Observe(event)
+	}
+}
+
+// Summary is an Observer which sums time spent on containers.
+type Summary struct {
+	mu           sync.Mutex
+	created      int
+	createTime   time.Duration
+	createFailed int
+	entered      int
+	waitTime     time.Duration
+	maxWait      time.Duration
+	terminated   int
+}
+
+func NewSummary() *Summary {
+	return &Summary{}
+}
+
+func (s *Summary) Observe(event Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch event := event.(type) {
+	case EventCreated:
+		s.created++
+		s.createTime += event.Duration
+	case EventCreateFailed:
+		s.createFailed++
+		s.createTime += event.Duration
+	case EventEntered:
+		s.entered++
+		s.waitTime += event.Wait
+		s.maxWait = max(s.maxWait, event.Wait)
+	case EventTerminated:
+		s.terminated++
+	}
+}
+
+func (s *Summary) Print(w io.Writer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fmt.Fprintf(w, "reusable containers summary:\n")
+	fmt.Fprintf(w, "  created %d containers in %s, %d failed\n", s.created, s.createTime.Round(time.Millisecond), s.createFailed)
+	fmt.Fprintf(w, "  entered %d times, waited %s in total, %s at most\n", s.entered, s.waitTime.Round(time.Millisecond), s.maxWait.Round(time.Millisecond))
+	fmt.Fprintf(w, "  terminated %d containers\n", s.terminated)
+}
+
+var runSummary = NewSummary()
+
+// RunSummary is observed by every daemon when CONTAINERS_SUMMARY is true, Main prints it after tests.
+func RunSummary() *Summary {
+	return runSummary
+}
+
+func summaryEnabled() bool {
+	env := os.Getenv("CONTAINERS_SUMMARY")
+
+	enabled, _ := strconv.ParseBool(env)
+
+	return enabled
+}
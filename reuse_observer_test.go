@@ -0,0 +1,72 @@
+package containers_test
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amidgo/containers"
+)
+
+func Test_ReuseDaemon_Observer(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	events := make(chan containers.Event, 10)
+	summary := containers.NewSummary()
+
+	ccf := containers.CreateContainerFunc[noopContainer](func(context.Context) (noopContainer, error) {
+		return noopContainer{}, nil
+	})
+
+	daemon := containers.RunReusableDaemon(ctx,
+		time.Millisecond,
+		ccf,
+		containers.WithObserver(containers.ObserverFunc(func(event containers.Event) { events <- event })),
+		containers.WithObserver(summary),
+	)
+
+	lease, err := daemon.Enter(containers.ContextWithTestName(ctx, "Test_Observed"))
+	if err != nil {
+		t.Fatalf("enter, expected no error, actual %s", err)
+	}
+
+	lease.Release()
+
+	expectedEvents := []reflect.Type{
+		reflect.TypeFor[containers.EventCreated](),
+		reflect.TypeFor[containers.EventEntered](),
+		reflect.TypeFor[containers.EventExited](),
+		reflect.TypeFor[containers.EventIdleTimerStarted](),
+		reflect.TypeFor[containers.EventTerminated](),
+	}
+
+	for _, expectedEvent := range expectedEvents {
+		select {
+		case event := <-events:
+			if reflect.TypeOf(event) != expectedEvent {
+				t.Fatalf("expected %s event, actual %T", expectedEvent, event)
+			}
+
+			entered, ok := event.(containers.EventEntered)
+			if ok && entered.TestName != "Test_Observed" {
+				t.Fatalf("expected entered event with test name, actual %+v", entered)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("%s event is not observed", expectedEvent)
+		}
+	}
+
+	output := &bytes.Buffer{}
+
+	summary.Print(output)
+
+	if !strings.Contains(output.String(), "created 1 containers") || !strings.Contains(output.String(), "entered 1 times") {
+		t.Fatalf("unexpected summary, %s", output)
+	}
+}
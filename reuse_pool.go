@@ -0,0 +1,135 @@
+package containers
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ReusablePool reuses up to size containers, every container is managed by its own ReusableDaemon,
+// so containers are created lazily and terminated after waitDuration without users.
+type ReusablePool[T Terminater] struct {
+	mu      sync.Mutex
+	daemons []*ReusableDaemon[T]
+	done    chan struct{}
+}
+
+// RunReusablePool applies opts to every daemon of the pool.
+func RunReusablePool[T Terminater](
+	ctx context.Context,
+	size int,
+	waitDuration time.Duration,
+	ccf CreateContainerFunc[T],
+	opts ...ReusableDaemonOption,
+) *ReusablePool[T] {
+	return runReusablePool(ctx, size, waitDuration, ccf, nil, opts...)
+}
+
+// RunSharedReusablePool is RunReusablePool sharing containers between processes,
+// sharing key gets the daemon index suffix, so every container of the pool is shared separately.
+func RunSharedReusablePool[T Terminater](
+	ctx context.Context,
+	size int,
+	waitDuration time.Duration,
+	ccf CreateContainerFunc[T],
+	sharing Sharing[T],
+	opts ...ReusableDaemonOption,
+) *ReusablePool[T] {
+	return runReusablePool(ctx, size, waitDuration, ccf, &sharing, opts...)
+}
+
+func runReusablePool[T Terminater](
+	ctx context.Context,
+	size int,
+	waitDuration time.Duration,
+	ccf CreateContainerFunc[T],
+	sharing *Sharing[T],
+	opts ...ReusableDaemonOption,
+) *ReusablePool[T] {
+	if size < 1 {
+		panic("reusable pool size must be positive, actual " + strconv.Itoa(size))
+	}
+
+	pool := &ReusablePool[T]{
+		daemons: make([]*ReusableDaemon[T], size),
+		done:    make(chan struct{}),
+	}
+
+	for i := range pool.daemons {
+		var daemonSharing *Sharing[T]
+
+		if sharing != nil {
+			daemonSharing = &Sharing[T]{
+				Key:    sharing.Key + "#" + strconv.Itoa(i),
+				Sharer: sharing.Sharer,
+			}
+		}
+
+		pool.daemons[i] = runReusableDaemon(ctx, waitDuration, ccf, daemonSharing, opts...)
+	}
+
+	go func() {
+		for _, daemon := range pool.daemons {
+			<-daemon.Done()
+		}
+
+		close(pool.done)
+	}()
+
+	return pool
+}
+
+func (p *ReusablePool[T]) Done() <-chan struct{} {
+	return p.done
+}
+
+// Enter assigns the caller to the daemon with the least number of held and requested leases.
+func (p *ReusablePool[T]) Enter(ctx context.Context) (*Lease[T], error) {
+	daemon := p.reserveLeastLoaded()
+
+	return daemon.enter(ctx, newLease(ctx, daemon))
+}
+
+func (p *ReusablePool[T]) reserveLeastLoaded() *ReusableDaemon[T] {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	leastLoaded := p.daemons[0]
+
+	for _, daemon := range p.daemons[1:] {
+		if daemon.load.Load() < leastLoaded.load.Load() {
+			leastLoaded = daemon
+		}
+	}
+
+	leastLoaded.load.Add(1)
+
+	return leastLoaded
+}
+
+// Warmup creates all containers of the pool concurrently.
+func (p *ReusablePool[T]) Warmup(ctx context.Context) error {
+	warmupers := make([]Warmuper, len(p.daemons))
+
+	for i, daemon := range p.daemons {
+		warmupers[i] = daemon
+	}
+
+	return Warmup(ctx, warmupers...)()
+}
+
+func (p *ReusablePool[T]) Leases() []LeaseInfo {
+	var infos []LeaseInfo
+
+	for _, daemon := range p.daemons {
+		infos = append(infos, daemon.Leases()...)
+	}
+
+	slices.SortFunc(infos, func(a, b LeaseInfo) int {
+		return a.AcquiredAt.Compare(b.AcquiredAt)
+	})
+
+	return infos
+}
@@ -0,0 +1,66 @@
+package containers_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/amidgo/containers"
+)
+
+func Test_ReusablePool_LeastLoaded(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	created := &atomic.Int64{}
+
+	pool := containers.RunReusablePool(ctx, 2, time.Minute, pingContainerCcf(created))
+
+	first, err := pool.Enter(ctx)
+	if err != nil {
+		t.Fatalf("first enter, expected no error, actual %s", err)
+	}
+
+	second, err := pool.Enter(ctx)
+	if err != nil {
+		t.Fatalf("second enter, expected no error, actual %s", err)
+	}
+
+	if first.Container() == second.Container() {
+		t.Fatal("expected users assigned to different containers")
+	}
+
+	third, err := pool.Enter(ctx)
+	if err != nil {
+		t.Fatalf("third enter, expected no error, actual %s", err)
+	}
+
+	if created.Load() != 2 {
+		t.Fatalf("expected 2 containers created, actual %d", created.Load())
+	}
+
+	first.Release()
+	third.Release()
+
+	fourth, err := pool.Enter(ctx)
+	if err != nil {
+		t.Fatalf("fourth enter, expected no error, actual %s", err)
+	}
+
+	if fourth.Container() != first.Container() {
+		t.Fatal("expected user assigned to the least loaded container")
+	}
+
+	fourth.Release()
+	second.Release()
+
+	cancel()
+	<-pool.Done()
+
+	if !first.Container().terminated.Load() || !second.Container().terminated.Load() {
+		t.Fatal("pool containers not terminated after stop")
+	}
+}
@@ -0,0 +1,85 @@
+package containers
+
+import (
+	"slices"
+	"time"
+)
+
+// WithMaxUses retires the container after it is handed out n times,
+// retired container is terminated when its last user exits and the next enter creates new one.
+func WithMaxUses(n int) ReusableDaemonOption {
+	return func(o *reusableDaemonOptions) {
+		o.maxUses = n
+	}
+}
+
+// WithMaxLifetime retires the container on the first enter after lifetime passed since its creation.
+func WithMaxLifetime(lifetime time.Duration) ReusableDaemonOption {
+	return func(o *reusableDaemonOptions) {
+		o.maxLifetime = lifetime
+	}
+}
+
+func (d *ReusableDaemon[T]) usedUp(inst *reusableInstance[T]) bool {
+	return d.maxUses > 0 && inst.uses >= d.maxUses
+}
+
+func (d *ReusableDaemon[T]) expired(inst *reusableInstance[T]) bool {
+	return d.maxLifetime > 0 && time.Since(inst.createdAt) >= d.maxLifetime
+}
+
+// retireCurrent stops handing out the current container, it is terminated right away if nobody uses it.
+// Shared container is retired for all processes, it is terminated when the last of them exits it.
+// Retirement runs outside of the loop, done is called after it.
+func (d *ReusableDaemon[T]) retireCurrent(done func()) {
+	inst := d.current
+
+	d.current = nil
+	d.idleCh = nil
+
+	if d.shared == nil {
+		if inst.users == 0 {
+			d.terminateInstance(inst, done)
+
+			return
+		}
+
+		d.retired = append(d.retired, inst)
+
+		if done != nil {
+			done()
+		}
+
+		return
+	}
+
+	d.leftGenerations = append(d.leftGenerations, inst.generation)
+
+	terminate := inst.users == 0
+	if terminate {
+		inst.terminated = true
+	} else {
+		d.retired = append(d.retired, inst)
+	}
+
+	d.detach(func() {
+		if done != nil {
+			defer done()
+		}
+
+		err := d.shared.retire(d.termCtx, inst.generation, inst.cnt)
+		if err != nil {
+			d.logger().Error("failed retire shared container", "error", err)
+		}
+
+		if terminate {
+			d.terminate(inst)
+		}
+	})
+}
+
+func (d *ReusableDaemon[T]) removeRetired(inst *reusableInstance[T]) {
+	d.retired = slices.DeleteFunc(d.retired, func(retired *reusableInstance[T]) bool {
+		return retired == inst
+	})
+}
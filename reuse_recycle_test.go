@@ -0,0 +1,229 @@
+package containers_test
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/amidgo/containers"
+)
+
+func Test_ReuseDaemon_MaxUses(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	created := &atomic.Int64{}
+
+	daemon := containers.RunReusableDaemon(ctx,
+		time.Minute,
+		pingContainerCcf(created),
+		containers.WithMaxUses(2),
+	)
+
+	first, err := daemon.Enter(ctx)
+	if err != nil {
+		t.Fatalf("first enter, expected no error, actual %s", err)
+	}
+
+	second, err := daemon.Enter(ctx)
+	if err != nil {
+		t.Fatalf("second enter, expected no error, actual %s", err)
+	}
+
+	third, err := daemon.Enter(ctx)
+	if err != nil {
+		t.Fatalf("third enter, expected no error, actual %s", err)
+	}
+
+	t.Cleanup(third.Release)
+
+	if third.Container().id != 2 {
+		t.Fatalf("expected new container after max uses, actual id %d", third.Container().id)
+	}
+
+	old := first.Container()
+
+	first.Release()
+
+	if old.terminated.Load() {
+		t.Fatal("retired container terminated while it has users")
+	}
+
+	second.Release()
+
+	if !old.terminated.Load() {
+		t.Fatal("retired container not terminated after last user exit")
+	}
+}
+
+func Test_ReuseDaemon_MaxLifetime(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	created := &atomic.Int64{}
+
+	daemon := containers.RunReusableDaemon(ctx,
+		time.Minute,
+		pingContainerCcf(created),
+		containers.WithMaxLifetime(time.Millisecond*10),
+	)
+
+	first, err := daemon.Enter(ctx)
+	if err != nil {
+		t.Fatalf("first enter, expected no error, actual %s", err)
+	}
+
+	first.Release()
+
+	<-time.After(time.Millisecond * 20)
+
+	second, err := daemon.Enter(ctx)
+	if err != nil {
+		t.Fatalf("second enter, expected no error, actual %s", err)
+	}
+
+	t.Cleanup(second.Release)
+
+	if second.Container().id != 2 {
+		t.Fatalf("expected new container after max lifetime, actual id %d", second.Container().id)
+	}
+
+	// expired container is terminated outside of the daemon loop
+	if !waitFor(time.Second, first.Container().terminated.Load) {
+		t.Fatal("expired container not terminated")
+	}
+}
+
+func waitFor(timeout time.Duration, cond func() bool) bool {
+	deadline := time.Now().Add(timeout)
+
+	for !cond() {
+		if time.Now().After(deadline) {
+			return false
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	return true
+}
+
+func Test_ReuseDaemon_Sharing_MaxUses(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	key := t.Name() + strconv.FormatInt(time.Now().UnixNano(), 10)
+
+	terminated := &atomic.Int64{}
+	created := atomic.Int64{}
+
+	ccf := containers.CreateContainerFunc[countingContainer](func(context.Context) (countingContainer, error) {
+		created.Add(1)
+
+		return countingContainer{terminated: terminated}, nil
+	})
+
+	sharer := &countingSharer{terminated: terminated}
+	sharing := containers.Sharing[countingContainer]{Key: key, Sharer: sharer}
+
+	first := containers.RunSharedReusableDaemon(ctx, time.Minute, ccf, sharing, containers.WithMaxUses(1))
+	second := containers.RunSharedReusableDaemon(ctx, time.Minute, ccf, sharing, containers.WithMaxUses(1))
+
+	firstLease, err := first.Enter(ctx)
+	if err != nil {
+		t.Fatalf("enter to first daemon, expected no error, actual %s", err)
+	}
+
+	secondLease, err := second.Enter(ctx)
+	if err != nil {
+		t.Fatalf("enter to second daemon, expected no error, actual %s", err)
+	}
+
+	if created.Load() != 2 {
+		t.Fatalf("expected used up shared container not attached, actual created %d", created.Load())
+	}
+
+	if sharer.attached.Load() != 0 {
+		t.Fatalf("expected used up shared container not attached, actual attached %d", sharer.attached.Load())
+	}
+
+	firstLease.Release()
+
+	if terminated.Load() != 1 {
+		t.Fatalf("expected retired shared container terminated after last user exit, actual terminated %d", terminated.Load())
+	}
+
+	secondLease.Release()
+
+	if terminated.Load() != 2 {
+		t.Fatalf("expected both shared containers terminated, actual terminated %d", terminated.Load())
+	}
+}
+
+func Test_ReuseDaemon_Sharing_RetiredByOtherProcess(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	key := t.Name() + strconv.FormatInt(time.Now().UnixNano(), 10)
+
+	terminated := &atomic.Int64{}
+	created := atomic.Int64{}
+
+	ccf := containers.CreateContainerFunc[countingContainer](func(context.Context) (countingContainer, error) {
+		created.Add(1)
+
+		return countingContainer{terminated: terminated}, nil
+	})
+
+	sharer := &countingSharer{terminated: terminated}
+	sharing := containers.Sharing[countingContainer]{Key: key, Sharer: sharer}
+
+	first := containers.RunSharedReusableDaemon(ctx, time.Minute, ccf, sharing, containers.WithMaxUses(2))
+	second := containers.RunSharedReusableDaemon(ctx, time.Minute, ccf, sharing, containers.WithMaxUses(2))
+
+	leases := make([]*containers.Lease[countingContainer], 0, 4)
+
+	enter := func(name string, daemon *containers.ReusableDaemon[countingContainer]) {
+		lease, err := daemon.Enter(ctx)
+		if err != nil {
+			t.Fatalf("enter to %s daemon, expected no error, actual %s", name, err)
+		}
+
+		leases = append(leases, lease)
+	}
+
+	enter("first", first)
+	enter("second", second)
+
+	if created.Load() != 1 || sharer.attached.Load() != 1 {
+		t.Fatalf("expected second daemon attached, actual created %d, attached %d", created.Load(), sharer.attached.Load())
+	}
+
+	// the second use retires the shared container for both daemons
+	enter("first", first)
+	enter("second", second)
+
+	if created.Load() != 2 {
+		t.Fatalf("expected retired shared container not handed out by second daemon, actual created %d", created.Load())
+	}
+
+	for _, lease := range leases[:3] {
+		lease.Release()
+	}
+
+	if terminated.Load() != 1 {
+		t.Fatalf("expected retired shared container terminated after last user exit, actual terminated %d", terminated.Load())
+	}
+
+	leases[3].Release()
+}
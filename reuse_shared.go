@@ -0,0 +1,457 @@
+package containers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Sharer describes a container so it can be attached from another process.
+type Sharer[T Terminater] interface {
+	Share(ctx context.Context, cnt T) ([]byte, error)
+	Attach(ctx context.Context, data []byte) (T, error)
+}
+
+const reuseAcrossProcessesEnvName = "CONTAINERS_REUSE_ACROSS_PROCESSES"
+
+func ReuseAcrossProcesses() bool {
+	env := os.Getenv(reuseAcrossProcessesEnvName)
+
+	enabled, _ := strconv.ParseBool(env)
+
+	return enabled
+}
+
+// Sharing makes daemons of all processes with the same Key, started by the same go test invocation,
+// use a single container, the last process leaving the container terminates it.
+type Sharing[T Terminater] struct {
+	Key    string
+	Sharer Sharer[T]
+}
+
+// RunSharedReusableDaemon is RunReusableDaemon sharing the container between processes.
+func RunSharedReusableDaemon[T Terminater](
+	ctx context.Context,
+	waitDuration time.Duration,
+	ccf CreateContainerFunc[T],
+	sharing Sharing[T],
+	opts ...ReusableDaemonOption,
+) *ReusableDaemon[T] {
+	return runReusableDaemon(ctx, waitDuration, ccf, &sharing, opts...)
+}
+
+const sharedLockRetryInterval = 50 * time.Millisecond
+
+// sharedState keeps a generation for every shared container,
+// retired generation isn't attached anymore and is terminated when its last process exits.
+type sharedState struct {
+	NextGeneration int                `json:"next_generation"`
+	Generations    []sharedGeneration `json:"generations"`
+}
+
+type sharedGeneration struct {
+	Generation int       `json:"generation"`
+	Data       []byte    `json:"data"`
+	Pids       []int     `json:"pids"`
+	CreatedAt  time.Time `json:"created_at"`
+	Retired    bool      `json:"retired"`
+	// Creating generation is reserved by the process creating its container, others wait until it is published.
+	Creating bool `json:"creating"`
+}
+
+func (s *sharedState) generation(generation int) *sharedGeneration {
+	idx := slices.IndexFunc(s.Generations, func(gen sharedGeneration) bool {
+		return gen.Generation == generation
+	})
+	if idx < 0 {
+		return nil
+	}
+
+	return &s.Generations[idx]
+}
+
+func (s *sharedState) remove(generation int) {
+	s.Generations = slices.DeleteFunc(s.Generations, func(gen sharedGeneration) bool {
+		return gen.Generation == generation
+	})
+}
+
+// sharedEntered is the container entered by this process with its generation.
+type sharedEntered[T Terminater] struct {
+	cnt        T
+	generation int
+	createdAt  time.Time
+}
+
+type sharedContainer[T Terminater] struct {
+	statePath string
+	lockPath  string
+	sharer    Sharer[T]
+	logger    func() *slog.Logger
+}
+
+func newSharedContainer[T Terminater](key string, sharer Sharer[T], logger func() *slog.Logger) *sharedContainer[T] {
+	// go test runs every package binary as a child of the same go command,
+	// so the parent pid scopes shared containers to a single go test invocation
+	hash := sha256.Sum256([]byte(strconv.Itoa(os.Getppid()) + ":" + key))
+	name := hex.EncodeToString(hash[:8])
+
+	dir := filepath.Join(os.TempDir(), "containers")
+
+	return &sharedContainer[T]{
+		statePath: filepath.Join(dir, name+".json"),
+		lockPath:  filepath.Join(dir, name+".lock"),
+		sharer:    sharer,
+		logger:    logger,
+	}
+}
+
+// enter attaches to the shared container or creates new one, generations in skip are never attached,
+// the state is locked only to reserve and publish the generation, not while the container is created.
+func (s *sharedContainer[T]) enter(ctx context.Context, ccf CreateContainerFunc[T], skip []int) (entered sharedEntered[T], err error) {
+	var reserved int
+
+	for {
+		var creating, attached bool
+
+		err = s.locked(ctx, func(state *sharedState) error {
+			gen, ok := attachableGeneration(state, skip)
+			switch {
+			case ok && gen.Creating:
+				creating = true
+
+				return nil
+			case ok:
+				entered, err = s.attach(ctx, *gen)
+				if err != nil {
+					return err
+				}
+
+				gen.Pids = append(gen.Pids, os.Getpid())
+				attached = true
+
+				return nil
+			}
+
+			reserved = state.NextGeneration
+
+			state.NextGeneration++
+			state.Generations = append(state.Generations, sharedGeneration{
+				Generation: reserved,
+				Pids:       []int{os.Getpid()},
+				Creating:   true,
+			})
+
+			return nil
+		})
+		switch {
+		case err != nil:
+			return entered, err
+		case attached:
+			return entered, nil
+		case !creating:
+			return s.create(ctx, ccf, reserved)
+		}
+
+		select {
+		case <-ctx.Done():
+			return entered, fmt.Errorf("wait for shared container creation, %w", context.Cause(ctx))
+		case <-time.After(sharedLockRetryInterval):
+		}
+	}
+}
+
+func attachableGeneration(state *sharedState, skip []int) (*sharedGeneration, bool) {
+	for i := range state.Generations {
+		gen := &state.Generations[i]
+
+		if !gen.Retired && !slices.Contains(skip, gen.Generation) {
+			return gen, true
+		}
+	}
+
+	return nil, false
+}
+
+// create runs ccf for the reserved generation and publishes the container, failed creation releases the reservation.
+func (s *sharedContainer[T]) create(
+	ctx context.Context,
+	ccf CreateContainerFunc[T],
+	generation int,
+) (entered sharedEntered[T], err error) {
+	var data []byte
+
+	cnt, err := ccf(ctx)
+	if err == nil {
+		data, err = s.sharer.Share(ctx, cnt)
+		if err != nil {
+			_ = cnt.Terminate(ctx)
+
+			err = fmt.Errorf("share container, %w", err)
+		}
+	}
+
+	entered = sharedEntered[T]{
+		cnt:        cnt,
+		generation: generation,
+		createdAt:  time.Now(),
+	}
+
+	// the reservation is released even if ctx is done, so other processes stop waiting for it
+	publishErr := s.locked(context.WithoutCancel(ctx), func(state *sharedState) error {
+		if err != nil {
+			state.remove(generation)
+
+			return nil
+		}
+
+		gen := state.generation(generation)
+		if gen == nil {
+			state.Generations = append(state.Generations, sharedGeneration{
+				Generation: generation,
+				Pids:       []int{os.Getpid()},
+			})
+
+			gen = &state.Generations[len(state.Generations)-1]
+		}
+
+		gen.Data = data
+		gen.CreatedAt = entered.createdAt
+		gen.Creating = false
+
+		return nil
+	})
+
+	switch {
+	case err != nil:
+		return entered, err
+	case publishErr != nil:
+		_ = cnt.Terminate(ctx)
+
+		return entered, fmt.Errorf("publish shared container, %w", publishErr)
+	}
+
+	return entered, nil
+}
+
+func (s *sharedContainer[T]) attach(ctx context.Context, gen sharedGeneration) (entered sharedEntered[T], err error) {
+	cnt, err := s.sharer.Attach(ctx, gen.Data)
+	if err != nil {
+		return entered, fmt.Errorf("attach to shared container, %w", err)
+	}
+
+	entered = sharedEntered[T]{
+		cnt:        cnt,
+		generation: gen.Generation,
+		createdAt:  gen.CreatedAt,
+	}
+
+	return entered, nil
+}
+
+// exit leaves the generation, the last process leaving it terminates the container.
+func (s *sharedContainer[T]) exit(ctx context.Context, generation int, cnt T) error {
+	return s.update(ctx, generation, func(gen *sharedGeneration) (terminate bool) {
+		idx := slices.Index(gen.Pids, os.Getpid())
+		if idx >= 0 {
+			gen.Pids = slices.Delete(gen.Pids, idx, idx+1)
+		}
+
+		return len(gen.Pids) == 0
+	}, cnt)
+}
+
+// retire stops other processes attaching to the generation, next enter of any process creates new container.
+func (s *sharedContainer[T]) retire(ctx context.Context, generation int, cnt T) error {
+	return s.update(ctx, generation, func(gen *sharedGeneration) (terminate bool) {
+		gen.Retired = true
+
+		return false
+	}, cnt)
+}
+
+// discard terminates shared container regardless of other processes using it,
+// next enter of any process creates new container.
+func (s *sharedContainer[T]) discard(ctx context.Context, generation int, cnt T) error {
+	return s.update(ctx, generation, func(*sharedGeneration) (terminate bool) {
+		return true
+	}, cnt)
+}
+
+// retired reports whether the generation is retired or discarded by any process.
+func (s *sharedContainer[T]) retired(ctx context.Context, generation int) (retired bool, err error) {
+	err = s.locked(ctx, func(state *sharedState) error {
+		gen := state.generation(generation)
+
+		retired = gen == nil || gen.Retired
+
+		return nil
+	})
+
+	return retired, err
+}
+
+// update changes the generation under the lock, terminated generation is removed from the state.
+func (s *sharedContainer[T]) update(
+	ctx context.Context,
+	generation int,
+	f func(gen *sharedGeneration) (terminate bool),
+	cnt T,
+) error {
+	var terminate bool
+
+	err := s.locked(ctx, func(state *sharedState) error {
+		gen := state.generation(generation)
+
+		// generation is already removed by the process which terminated it
+		if gen == nil {
+			return nil
+		}
+
+		terminate = f(gen)
+		if terminate {
+			state.remove(generation)
+		}
+
+		return nil
+	})
+	if err != nil || !terminate {
+		return err
+	}
+
+	return cnt.Terminate(ctx)
+}
+
+// locked changes the state under the lock, generations orphaned by crashed processes
+// are removed from the state and terminated after the lock is released.
+func (s *sharedContainer[T]) locked(ctx context.Context, f func(state *sharedState) error) error {
+	unlock, err := s.lock(ctx)
+	if err != nil {
+		return err
+	}
+
+	state, orphaned, err := s.readState()
+	if err == nil {
+		err = f(&state)
+	}
+
+	if err == nil {
+		err = s.writeState(state)
+	}
+
+	unlock()
+
+	s.terminateOrphaned(ctx, orphaned)
+
+	return err
+}
+
+func (s *sharedContainer[T]) terminateOrphaned(ctx context.Context, orphaned []sharedGeneration) {
+	for _, gen := range orphaned {
+		// container of the interrupted creation isn't published, it is left to the prune command
+		if gen.Creating {
+			continue
+		}
+
+		cnt, err := s.sharer.Attach(ctx, gen.Data)
+		if err == nil {
+			err = cnt.Terminate(ctx)
+		}
+
+		if err != nil {
+			s.logger().Error("failed terminate shared container orphaned by crashed process",
+				"generation", gen.Generation,
+				"error", err,
+			)
+		}
+	}
+}
+
+func (s *sharedContainer[T]) lock(ctx context.Context) (unlock func(), err error) {
+	err = os.MkdirAll(filepath.Dir(s.lockPath), 0o755)
+	if err != nil {
+		return nil, fmt.Errorf("create shared state dir, %w", err)
+	}
+
+	for {
+		unlock, err = lockFile(s.lockPath)
+		switch {
+		case err == nil:
+			return unlock, nil
+		case !errors.Is(err, errFileLocked):
+			return nil, fmt.Errorf("lock shared state, %w", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("lock shared state, %w", context.Cause(ctx))
+		case <-time.After(sharedLockRetryInterval):
+		}
+	}
+}
+
+func (s *sharedContainer[T]) readState() (state sharedState, orphaned []sharedGeneration, err error) {
+	data, err := os.ReadFile(s.statePath)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return state, nil, nil
+	case err != nil:
+		return state, nil, fmt.Errorf("read shared state, %w", err)
+	}
+
+	err = json.Unmarshal(data, &state)
+	if err != nil {
+		return state, nil, fmt.Errorf("decode shared state, %w", err)
+	}
+
+	// processes that crashed without exit are not holding the container anymore,
+	// the state file is kept, so generations are never reused within the go test invocation
+	for i := range state.Generations {
+		state.Generations[i].Pids = slices.DeleteFunc(state.Generations[i].Pids, func(pid int) bool {
+			return !processAlive(pid)
+		})
+	}
+
+	state.Generations = slices.DeleteFunc(state.Generations, func(gen sharedGeneration) bool {
+		if len(gen.Pids) > 0 {
+			return false
+		}
+
+		orphaned = append(orphaned, gen)
+
+		return true
+	})
+
+	return state, orphaned, nil
+}
+
+func (s *sharedContainer[T]) writeState(state sharedState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("encode shared state, %w", err)
+	}
+
+	// the process may exit in the middle of the write, so the state is replaced by rename
+	tmpPath := s.statePath + ".tmp"
+
+	err = os.WriteFile(tmpPath, data, 0o644)
+	if err != nil {
+		return fmt.Errorf("write shared state, %w", err)
+	}
+
+	err = os.Rename(tmpPath, s.statePath)
+	if err != nil {
+		return fmt.Errorf("replace shared state, %w", err)
+	}
+
+	return nil
+}
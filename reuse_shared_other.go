@@ -0,0 +1,18 @@
+//go:build !linux && !darwin
+
+package containers
+
+import (
+	"errors"
+	"runtime"
+)
+
+var errFileLocked = errors.New("file is locked")
+
+func lockFile(string) (unlock func(), err error) {
+	return nil, errors.New("reuse across processes is not supported on " + runtime.GOOS)
+}
+
+func processAlive(int) bool {
+	return false
+}
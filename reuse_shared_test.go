@@ -0,0 +1,98 @@
+package containers_test
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/amidgo/containers"
+)
+
+type countingContainer struct {
+	terminated *atomic.Int64
+}
+
+func (c countingContainer) Terminate(context.Context) error {
+	c.terminated.Add(1)
+
+	return nil
+}
+
+type countingSharer struct {
+	terminated *atomic.Int64
+	attached   atomic.Int64
+}
+
+func (s *countingSharer) Share(context.Context, countingContainer) ([]byte, error) {
+	return []byte("shared"), nil
+}
+
+func (s *countingSharer) Attach(context.Context, []byte) (countingContainer, error) {
+	s.attached.Add(1)
+
+	return countingContainer{terminated: s.terminated}, nil
+}
+
+func Test_ReuseDaemon_Sharing(t *testing.T) {
+	t.Parallel()
+
+	key := t.Name() + strconv.FormatInt(time.Now().UnixNano(), 10)
+
+	terminated := &atomic.Int64{}
+	created := atomic.Int64{}
+
+	ccf := containers.CreateContainerFunc[countingContainer](func(ctx context.Context) (countingContainer, error) {
+		created.Add(1)
+
+		return countingContainer{terminated: terminated}, nil
+	})
+
+	sharer := &countingSharer{terminated: terminated}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	firstCtx, firstCancel := context.WithCancel(ctx)
+	secondCtx, secondCancel := context.WithCancel(ctx)
+
+	sharing := containers.Sharing[countingContainer]{Key: key, Sharer: sharer}
+
+	first := containers.RunSharedReusableDaemon(firstCtx, time.Millisecond, ccf, sharing)
+	second := containers.RunSharedReusableDaemon(secondCtx, time.Millisecond, ccf, sharing)
+
+	firstLease, err := first.Enter(ctx)
+	if err != nil {
+		t.Fatalf("enter to first daemon, expected no error, actual %s", err)
+	}
+
+	secondLease, err := second.Enter(ctx)
+	if err != nil {
+		t.Fatalf("enter to second daemon, expected no error, actual %s", err)
+	}
+
+	if created.Load() != 1 {
+		t.Fatalf("expected container created once, actual %d", created.Load())
+	}
+
+	if sharer.attached.Load() != 1 {
+		t.Fatalf("expected container attached once, actual %d", sharer.attached.Load())
+	}
+
+	firstLease.Release()
+	firstCancel()
+	<-first.Done()
+
+	if terminated.Load() != 0 {
+		t.Fatal("shared container terminated while second daemon uses it")
+	}
+
+	secondLease.Release()
+	secondCancel()
+	<-second.Done()
+
+	if terminated.Load() != 1 {
+		t.Fatalf("expected shared container terminated once, actual %d", terminated.Load())
+	}
+}
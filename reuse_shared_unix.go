@@ -0,0 +1,44 @@
+//go:build linux || darwin
+
+package containers
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+var errFileLocked = errors.New("file is locked")
+
+func lockFile(path string) (unlock func(), err error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open lock file, %w", err)
+	}
+
+	err = syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	switch {
+	case errors.Is(err, syscall.EWOULDBLOCK):
+		_ = file.Close()
+
+		return nil, errFileLocked
+	case err != nil:
+		_ = file.Close()
+
+		return nil, fmt.Errorf("flock, %w", err)
+	}
+
+	unlock = func() {
+		_ = syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+		_ = file.Close()
+	}
+
+	return unlock, nil
+}
+
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
@@ -46,6 +46,12 @@ func (m *mockTerminater) assert() {
 	}
 }
 
+type noopContainer struct{}
+
+func (noopContainer) Terminate(context.Context) error {
+	return nil
+}
+
 func Test_ReuseDaemon_Zero_User_Exit(t *testing.T) {
 	t.Parallel()
 
@@ -55,7 +61,7 @@ func Test_ReuseDaemon_Zero_User_Exit(t *testing.T) {
 	cnt := newMockTerminater(t)
 	errDoubleCffCall := errors.New("unexpected, second call to ccf")
 
-	ccf := containers.CreateContainerFunc(func(ctx context.Context) (any, error) {
+	ccf := containers.CreateContainerFunc[*mockTerminater](func(ctx context.Context) (*mockTerminater, error) {
 		if called {
 			return nil, errDoubleCffCall
 		}
@@ -89,6 +95,20 @@ func Test_ReuseDaemon_Zero_User_Exit(t *testing.T) {
 	}()
 
 	wg.Wait()
+
+	awaitTerminated(t, cnt, waitDuration*2)
+}
+
+func awaitTerminated(t *testing.T, cnt *mockTerminater, timeout time.Duration) {
+	deadline := time.After(timeout)
+
+	for !cnt.terminated.Load() {
+		select {
+		case <-deadline:
+			t.Fatalf("container is not terminated after %s", timeout)
+		case <-time.After(time.Millisecond * 10):
+		}
+	}
 }
 
 func Test_ManyConcurrentEnterAndExit(t *testing.T) {
@@ -99,16 +119,16 @@ func Test_ManyConcurrentEnterAndExit(t *testing.T) {
 	called := false
 	errDoubleCffCall := errors.New("unexpected, second call to ccf")
 
-	ccf := containers.CreateContainerFunc(func(ctx context.Context) (any, error) {
+	ccf := containers.CreateContainerFunc[noopContainer](func(ctx context.Context) (noopContainer, error) {
 		<-time.After(time.Second * 2)
 
 		if called {
-			return false, errDoubleCffCall
+			return noopContainer{}, errDoubleCffCall
 		}
 
 		called = true
 
-		return true, nil
+		return noopContainer{}, nil
 	})
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -124,8 +144,8 @@ func Test_ManyConcurrentEnterAndExit(t *testing.T) {
 	runConcurrentEnters(t, ctx, daemon, count)
 }
 
-func runConcurrentEnters(t *testing.T, ctx context.Context, daemon *containers.ReusableDaemon, count int) {
-	sendCh := make(chan struct{})
+func runConcurrentEnters(t *testing.T, ctx context.Context, daemon *containers.ReusableDaemon[noopContainer], count int) {
+	sendCh := make(chan *containers.Lease[noopContainer])
 	defer close(sendCh)
 
 	ctx, cancel := context.WithCancelCause(ctx)
@@ -133,7 +153,7 @@ func runConcurrentEnters(t *testing.T, ctx context.Context, daemon *containers.R
 
 	for range count {
 		go func() {
-			_, err := daemon.Enter(ctx)
+			lease, err := daemon.Enter(ctx)
 			if err != nil {
 				cancel(err)
 			}
@@ -141,7 +161,7 @@ func runConcurrentEnters(t *testing.T, ctx context.Context, daemon *containers.R
 			sleepDuration := time.Duration(rand.IntN(1000)) * time.Millisecond
 			<-time.After(sleepDuration)
 
-			sendCh <- struct{}{}
+			sendCh <- lease
 		}()
 	}
 
@@ -153,10 +173,12 @@ func runConcurrentEnters(t *testing.T, ctx context.Context, daemon *containers.R
 			t.Fatal(context.Cause(ctx))
 
 			return
-		case <-sendCh:
+		case lease := <-sendCh:
 			entered++
 
-			daemon.Exit()
+			if lease != nil {
+				lease.Release()
+			}
 
 			if entered == count {
 				return
@@ -167,24 +189,24 @@ func runConcurrentEnters(t *testing.T, ctx context.Context, daemon *containers.R
 
 func simpleEnterAndExit(
 	t *testing.T,
-	daemon *containers.ReusableDaemon,
+	daemon *containers.ReusableDaemon[*mockTerminater],
 	notify func(),
-	expectedCnt any,
+	expectedCnt *mockTerminater,
 	waitDuration time.Duration,
 ) {
 	ctx, cancel := context.WithCancel(context.Background())
 	t.Cleanup(cancel)
 
-	cnt, err := daemon.Enter(ctx)
+	lease, err := daemon.Enter(ctx)
 	if err != nil {
 		t.Fatalf("enter to daemon, expected no error, actual %s", err)
 	}
 
-	if !reflect.DeepEqual(expectedCnt, cnt) {
-		t.Fatalf("enter to daemon, expected %+v, actual %+v", expectedCnt, cnt)
+	if !reflect.DeepEqual(expectedCnt, lease.Container()) {
+		t.Fatalf("enter to daemon, expected %+v, actual %+v", expectedCnt, lease.Container())
 	}
 
-	go daemon.Exit()
+	go lease.Release()
 
 	<-time.After(waitDuration / 2)
 
@@ -193,25 +215,25 @@ func simpleEnterAndExit(
 
 func awaitNotifyEnterAndExit(
 	t *testing.T,
-	daemon *containers.ReusableDaemon,
+	daemon *containers.ReusableDaemon[*mockTerminater],
 	notifyCtx context.Context,
-	expectedCnt any,
+	expectedCnt *mockTerminater,
 ) {
 	<-notifyCtx.Done()
 
 	ctx, cancel := context.WithCancel(context.Background())
 	t.Cleanup(cancel)
 
-	cnt, err := daemon.Enter(ctx)
+	lease, err := daemon.Enter(ctx)
 	if err != nil {
 		t.Fatalf("enter to daemon, expected no error, actual %s", err)
 	}
 
-	if !reflect.DeepEqual(expectedCnt, cnt) {
-		t.Fatalf("enter to daemon, expected %+v, actual %+v", expectedCnt, cnt)
+	if !reflect.DeepEqual(expectedCnt, lease.Container()) {
+		t.Fatalf("enter to daemon, expected %+v, actual %+v", expectedCnt, lease.Container())
 	}
 
-	daemon.Exit()
+	lease.Release()
 }
 
 func Test_ReuseDaemon_Cancel_Root_Context(t *testing.T) {
@@ -233,7 +255,7 @@ func canceledCtx(t *testing.T) {
 
 	called := false
 
-	ccf := containers.CreateContainerFunc(func(ctx context.Context) (any, error) {
+	ccf := containers.CreateContainerFunc[*mockTerminater](func(ctx context.Context) (*mockTerminater, error) {
 		if called {
 			t.Fatal("ccf called twice")
 		}
@@ -251,18 +273,18 @@ func canceledCtx(t *testing.T) {
 		ccf,
 	)
 
-	enterCnt, err := daemon.Enter(ctx)
+	lease, err := daemon.Enter(ctx)
 	if err != nil {
 		t.Fatalf("expected no error, actual %+v", err)
 	}
 
-	if !reflect.DeepEqual(enterCnt, cnt) {
-		t.Fatalf("wrong enterCnt, expected %+v, actual %+v", cnt, enterCnt)
+	if !reflect.DeepEqual(lease.Container(), cnt) {
+		t.Fatalf("wrong enterCnt, expected %+v, actual %+v", cnt, lease.Container())
 	}
 
 	rootCancel()
 
-	enterCnt, err = daemon.Enter(ctx)
+	enterLease, err := daemon.Enter(ctx)
 	if !errors.Is(err, context.Canceled) {
 		t.Fatalf(
 			"unexpected error of entering in canceled daemon, expected context.Canceled, actual %+v",
@@ -270,13 +292,13 @@ func canceledCtx(t *testing.T) {
 		)
 	}
 
-	if enterCnt != nil {
-		t.Fatalf("unexpected cnt, expected nil, actual %+v", cnt)
+	if enterLease != nil {
+		t.Fatalf("unexpected lease, expected nil, actual %+v", enterLease)
 	}
 
-	daemon.Exit()
+	lease.Release()
 
-	enterCnt, err = daemon.Enter(ctx)
+	_, err = daemon.Enter(ctx)
 	if !errors.Is(err, context.Canceled) {
 		t.Fatalf(
 			"unexpected error of entering in canceled daemon, expected context.Canceled, actual %+v",
@@ -293,23 +315,30 @@ func inTimeCanceledCtx(t *testing.T) {
 
 	waitDuration := time.Millisecond
 
-	ccf := containers.CreateContainerFunc(func(ctx context.Context) (any, error) {
+	ccf := containers.CreateContainerFunc[*mockTerminater](func(ctx context.Context) (*mockTerminater, error) {
 		return newMockTerminater(t), nil
 	})
 
-	runInTimeCanceledReuseDaemon(
-		t,
-		ctx,
-		waitDuration,
-		ccf,
-	)
+	runCount := 100
+	if testing.Short() {
+		runCount = 10
+	}
+
+	for range runCount {
+		runInTimeCanceledReuseDaemon(
+			t,
+			ctx,
+			waitDuration,
+			ccf,
+		)
+	}
 }
 
 func runInTimeCanceledReuseDaemon(
 	t *testing.T,
 	ctx context.Context,
 	waitDuration time.Duration,
-	ccf containers.CreateContainerFunc,
+	ccf containers.CreateContainerFunc[*mockTerminater],
 ) {
 	rootCtx, rootCancel := context.WithCancel(ctx)
 
@@ -329,69 +358,96 @@ func runInTimeCanceledReuseDaemon(
 
 	<-timeCtx.Done()
 
-	_, err := daemon.Enter(ctx)
+	lease, err := daemon.Enter(ctx)
 	switch err {
 	case nil:
-		daemon.Exit()
-
-		runInTimeCanceledReuseDaemon(
-			t,
-			ctx,
-			waitDuration,
-			ccf,
-		)
+		lease.Release()
 	default:
 		if !errors.Is(err, context.Canceled) {
 			t.Fatalf("unexpected error, expected context.Canceled, actual %+v", err)
 		}
-
-		runInTimeCanceledReuseDaemonWhileCanceled(
-			t,
-			ctx,
-			waitDuration,
-			ccf,
-		)
+		runInTimeCanceledReuseDaemonWhileCanceled(t, ctx, daemon)
 	}
+
+	<-daemon.Done()
 }
 
 func runInTimeCanceledReuseDaemonWhileCanceled(
 	t *testing.T,
 	ctx context.Context,
-	waitDuration time.Duration,
-	ccf containers.CreateContainerFunc,
+	daemon *containers.ReusableDaemon[*mockTerminater],
 ) {
-	rootCtx, rootCancel := context.WithCancel(ctx)
+	for range 10 {
+		lease, err := daemon.Enter(ctx)
+		if err == nil {
+			lease.Release()
+			t.Fatalf("unexpected enter success after root context is canceled")
+		}
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("unexpected error, expected context.Canceled, actual %+v", err)
+		}
+	}
+}
 
-	daemon := containers.RunReusableDaemon(
-		rootCtx,
-		waitDuration,
-		ccf,
-	)
+func Test_ReuseDaemon_Enter_Abandoned_While_Creating(t *testing.T) {
+	t.Parallel()
 
-	timeCtx, timeCancel := context.WithTimeout(ctx, time.Millisecond*10)
-	t.Cleanup(timeCancel)
+	cnt := newMockTerminater(t)
+	created := atomic.Int64{}
+	release := make(chan struct{})
+
+	ccf := containers.CreateContainerFunc[*mockTerminater](func(ctx context.Context) (*mockTerminater, error) {
+		created.Add(1)
+
+		<-release
+
+		return cnt, nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	waitDuration := time.Millisecond * 10
+
+	daemon := containers.RunReusableDaemon(ctx, waitDuration, ccf)
+
+	firstCtx, firstCancel := context.WithTimeout(ctx, time.Millisecond*10)
+	t.Cleanup(firstCancel)
+
+	_, err := daemon.Enter(firstCtx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("enter with expired ctx, expected context.DeadlineExceeded, actual %+v", err)
+	}
+
+	secondResult := make(chan error, 1)
+	secondLease := make(chan *containers.Lease[*mockTerminater], 1)
 
 	go func() {
-		<-timeCtx.Done()
-		rootCancel()
+		lease, err := daemon.Enter(ctx)
+		if err == nil {
+			secondLease <- lease
+		}
+
+		secondResult <- err
 	}()
 
-	<-timeCtx.Done()
+	close(release)
 
-	_, err := daemon.Enter(ctx)
-	switch err {
-	case nil:
-		daemon.Exit()
-	default:
-		if !errors.Is(err, context.Canceled) {
-			t.Fatalf("unexpected error, expected context.Canceled, actual %+v", err)
-		}
+	err = <-secondResult
+	if err != nil {
+		t.Fatalf("enter after abandoned enter, expected no error, actual %s", err)
+	}
 
-		runInTimeCanceledReuseDaemonWhileCanceled(
-			t,
-			ctx,
-			waitDuration,
-			ccf,
-		)
+	if created.Load() != 1 {
+		t.Fatalf("expected ccf called once, actual %d", created.Load())
+	}
+
+	lease := <-secondLease
+	if lease.Container() != cnt {
+		t.Fatalf("unexpected container %+v", lease.Container())
 	}
+
+	lease.Release()
+
+	awaitTerminated(t, cnt, time.Second)
 }
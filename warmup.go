@@ -0,0 +1,39 @@
+package containers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+type Warmuper interface {
+	Warmup(ctx context.Context) error
+}
+
+// Warmup starts creation of containers of all reusables concurrently in the background,
+// returned wait func blocks until every container is created, waiting is optional.
+func Warmup(ctx context.Context, reusables ...Warmuper) (wait func() error) {
+	errs := make([]error, len(reusables))
+
+	wg := &sync.WaitGroup{}
+
+	for i, reusable := range reusables {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			err := reusable.Warmup(ctx)
+			if err != nil {
+				errs[i] = fmt.Errorf("warmup %T, %w", reusable, err)
+			}
+		}()
+	}
+
+	return func() error {
+		wg.Wait()
+
+		return errors.Join(errs...)
+	}
+}
@@ -0,0 +1,106 @@
+package containers_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/amidgo/containers"
+)
+
+func Test_ReuseDaemon_Warmup(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	created := atomic.Int64{}
+	cnt := newMockTerminater(t)
+
+	ccf := containers.CreateContainerFunc[*mockTerminater](func(context.Context) (*mockTerminater, error) {
+		created.Add(1)
+
+		return cnt, nil
+	})
+
+	daemon := containers.RunReusableDaemon(ctx, time.Second, ccf)
+
+	err := daemon.Warmup(ctx)
+	if err != nil {
+		t.Fatalf("warmup, expected no error, actual %s", err)
+	}
+
+	if cnt.terminated.Load() {
+		t.Fatal("warm container terminated before use")
+	}
+
+	lease, err := daemon.Enter(ctx)
+	if err != nil {
+		t.Fatalf("enter, expected no error, actual %s", err)
+	}
+
+	if created.Load() != 1 {
+		t.Fatalf("expected warm container reused, actual created %d", created.Load())
+	}
+
+	lease.Release()
+
+	awaitTerminated(t, cnt, time.Second*2)
+}
+
+func Test_ReuseDaemon_Warmup_Idle(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	cnt := newMockTerminater(t)
+
+	ccf := containers.CreateContainerFunc[*mockTerminater](func(context.Context) (*mockTerminater, error) {
+		return cnt, nil
+	})
+
+	daemon := containers.RunReusableDaemon(ctx, time.Millisecond, ccf)
+
+	err := daemon.Warmup(ctx)
+	if err != nil {
+		t.Fatalf("warmup, expected no error, actual %s", err)
+	}
+
+	awaitTerminated(t, cnt, time.Second)
+}
+
+type warmuperFunc func(ctx context.Context) error
+
+func (f warmuperFunc) Warmup(ctx context.Context) error {
+	return f(ctx)
+}
+
+func Test_Warmup(t *testing.T) {
+	t.Parallel()
+
+	start := make(chan struct{})
+	errWarmup := errors.New("warmup failed")
+
+	blocking := warmuperFunc(func(context.Context) error {
+		<-start
+
+		return nil
+	})
+
+	// blocking warmup is finished only if failing one runs concurrently
+	failing := warmuperFunc(func(context.Context) error {
+		close(start)
+
+		return errWarmup
+	})
+
+	wait := containers.Warmup(context.Background(), blocking, failing)
+
+	err := wait()
+	if !errors.Is(err, errWarmup) {
+		t.Fatalf("expected warmup error, actual %v", err)
+	}
+}